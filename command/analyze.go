@@ -3,16 +3,33 @@ package command
 import (
 	"github.com/abzicht/gpsplit/gpxtransform"
 	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"gonum.org/v1/gonum/unit"
 )
 
 type AnalyzeCommand struct {
-	Num bool `short:"c" long:"count" description:"Only analyze object counts."`
+	ElevationSmoothingFlags
+	Num                bool   `short:"c" long:"count" description:"Only analyze object counts."`
+	Elevation          bool   `short:"e" long:"elevation" description:"Only analyze ascent and descent. Elevation changes smaller than --elevation-threshold are ignored."`
+	Climbs             bool   `long:"climbs" description:"Only analyze climbs, i.e., list every climb from a valley to a summit that gains at least --prominence with its length, gain, average and maximum grade, and category."`
+	Prominence         Length `long:"prominence" description:"The elevation difference that summits and valleys must at least have to both neighboring valleys and summits (cf. --climbs). Valid formats: 30 (30 meters); 30m; 0.05km." default:"30"`
+	ElevationThreshold Length `long:"elevation-threshold" description:"Elevation changes that are smaller than this value are considered noise and do not add to ascent or descent. Valid formats: 5 (5 meters); 5m; 0.01km." default:"5"`
 }
 
 func (a AnalyzeCommand) GetConfiguration() (tc config.TransformConfig, err error) {
 	tc = config.NewTransformConfig()
+	smoothing, ok, err := a.ElevationSmoothingFlags.options()
+	if err != nil {
+		return
+	}
+	if ok {
+		tc = config.WithSegmentTransform(gpxtransform.Direct(smoothing))(tc)
+	}
 	if a.Num {
 		tc = config.WithFileTransform(gpxtransform.CountFile())(tc)
+	} else if a.Elevation {
+		tc = config.WithFileTransform(gpxtransform.AnalyzeElevation(unit.Length(a.ElevationThreshold)))(tc)
+	} else if a.Climbs {
+		tc = config.WithFileTransform(gpxtransform.AnalyzeClimbs(unit.Length(a.Prominence)))(tc)
 	} else {
 		tc = config.WithFileTransform(gpxtransform.AnalyzeFile())(tc)
 	}
@@ -0,0 +1,136 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+)
+
+/*
+CalendarFlags holds the arguments of commands that select points or segments
+by the time at which they were recorded.
+*/
+type CalendarFlags struct {
+	From      string   `long:"from" description:"Select everything recorded at or after the provided time. Valid formats: 2024-05-01; 2024-05-01T07:30:00; 2024-05-01T07:30:00+02:00." default:""`
+	To        string   `long:"to" description:"Select everything recorded before the provided time. If only a date is provided, the whole day is included. Valid formats: cf. --from." default:""`
+	Weekday   []string `long:"weekday" description:"Select everything recorded on the provided weekday (mon, tue, wed, thu, fri, sat, sun). Can be provided multiple times."`
+	TimeOfDay string   `long:"time-of-day" description:"Select everything recorded within the provided local time of day (START-END). Ranges may wrap around midnight. Valid formats: 07:00-09:30; 22:00-06:00." default:""`
+	TimeZone  string   `long:"tz" description:"The IANA time zone that --from, --to, --weekday, and --time-of-day refer to, e.g., Europe/Berlin." default:"UTC"`
+	DropTime  bool     `long:"drop-time" description:"Drop instead of keep everything that is selected by --from, --to, --weekday, and --time-of-day."`
+}
+
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+/*
+condition returns the time condition described by the calendar flags. ok is
+false, if no calendar flag was provided.
+*/
+func (c CalendarFlags) condition() (condition options.TimeCondition, ok bool, err error) {
+	location, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return
+	}
+	conditions := []options.TimeCondition{}
+	if 0 != len(c.From) || 0 != len(c.To) {
+		var from, to time.Time
+		if 0 != len(c.From) {
+			from, _, err = parseCalendarTime(c.From, location)
+			if err != nil {
+				return
+			}
+		}
+		if 0 != len(c.To) {
+			var dateOnly bool
+			to, dateOnly, err = parseCalendarTime(c.To, location)
+			if err != nil {
+				return
+			}
+			if dateOnly {
+				to = to.AddDate(0, 0, 1)
+			}
+		}
+		conditions = append(conditions, options.TimeRange(from, to))
+	}
+	if 0 != len(c.Weekday) {
+		days := []time.Weekday{}
+		for _, day := range c.Weekday {
+			weekday, found := weekdays[strings.ToLower(day)]
+			if !found {
+				err = CommandError{fmt.Sprintf("unknown weekday: %v", day)}
+				return
+			}
+			days = append(days, weekday)
+		}
+		conditions = append(conditions, options.Weekdays(location, days...))
+	}
+	if 0 != len(c.TimeOfDay) {
+		startAndEnd := strings.Split(c.TimeOfDay, "-")
+		if 2 != len(startAndEnd) {
+			err = CommandError{"incorrect format for time-of-day; expecting format START-END"}
+			return
+		}
+		var start, end time.Duration
+		start, err = parseClock(startAndEnd[0])
+		if err != nil {
+			return
+		}
+		end, err = parseClock(startAndEnd[1])
+		if err != nil {
+			return
+		}
+		conditions = append(conditions, options.TimeOfDay(location, start, end))
+	}
+	if 0 == len(conditions) {
+		return
+	}
+	return options.AllTime(conditions...), true, nil
+}
+
+/*
+parseCalendarTime parses a date or date-time in the provided location.
+dateOnly is true, if value holds no time of day.
+*/
+func parseCalendarTime(value string, location *time.Location) (t time.Time, dateOnly bool, err error) {
+	t, err = time.ParseInLocation(time.DateOnly, value, location)
+	if err == nil {
+		return t, true, nil
+	}
+	for _, layout := range dateLayouts {
+		t, err = time.ParseInLocation(layout, value, location)
+		if err == nil {
+			return
+		}
+	}
+	err = CommandError{fmt.Sprintf("incorrect format for time: %v", value)}
+	return
+}
+
+/*
+parseClock parses a local time of day (15:04 or 15:04:05) into the offset from midnight.
+*/
+func parseClock(value string) (offset time.Duration, err error) {
+	value = strings.TrimSpace(value)
+	var t time.Time
+	t, err = time.Parse("15:04", value)
+	if err != nil {
+		t, err = time.Parse("15:04:05", value)
+	}
+	if err != nil {
+		err = CommandError{fmt.Sprintf("incorrect format for time of day: %v", value)}
+		return
+	}
+	offset = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	return
+}
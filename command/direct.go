@@ -1,8 +1,10 @@
 package command
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/abzicht/gpsplit/gpxio"
 	"github.com/abzicht/gpsplit/gpxtransform"
 	"github.com/abzicht/gpsplit/gpxtransform/config"
 	"github.com/abzicht/gpsplit/gpxtransform/options"
@@ -10,13 +12,28 @@ import (
 )
 
 type DirectCommand struct {
-	Simplify    unit.Length   `short:"s" long:"simplify" description:"Simplify tracks using Ramer-Douglas-Peucker algorithm with the provided distance in meters." default:"0"`
-	RemoveStops bool          `long:"remove-stops" description:"Remove points that are considered to have no movement. Cf. command 'split' for splitting segments at stops."`
-	MinPoints   int           `long:"min-points" description:"Remove segments that have less points than the provided number." default:"0"`
-	MinRadius   unit.Length   `long:"min-radius" description:"Remove segments whose points are all within a given radius from the starting point." default:"0"`
-	MinDistance unit.Length   `long:"min-distance" description:"Remove segments that are shorter than the provided min distance." default:"0"`
-	MinDuration time.Duration `long:"min-duration" description:"Remove segments that are shorter than the provided min duration." default:"0"`
-	MaxDuration time.Duration `long:"max-duration" description:"Remove segments that are longer than the provided max duration." default:"0"`
+	CalendarFlags
+	ElevationSmoothingFlags
+	Simplify         unit.Length   `short:"s" long:"simplify" description:"Simplify tracks using Ramer-Douglas-Peucker algorithm with the provided distance in meters." default:"0"`
+	SimplifyTime     unit.Length   `long:"simplify-time" description:"Simplify tracks using a time-aware Ramer-Douglas-Peucker algorithm with the provided distance in meters: points are removed, if their position can be reconstructed from their timestamp within that distance. Preserves speeds and stops." default:"0"`
+	SimplifyArea     float64       `long:"simplify-area" description:"Simplify tracks using Visvalingam-Whyatt algorithm: points are removed while the triangle formed with their neighbors has a smaller area than the provided value in square meters." default:"0"`
+	SimplifyPoints   int           `long:"simplify-points" description:"Simplify tracks until they hold at most the provided number of points, removing the least significant points first (Visvalingam-Whyatt)." default:"0"`
+	RemoveStops      bool          `long:"remove-stops" description:"Remove points that are considered to have no movement. Cf. command 'split' for splitting segments at stops."`
+	MinPoints        int           `long:"min-points" description:"Remove segments that have less points than the provided number." default:"0"`
+	MinRadius        unit.Length   `long:"min-radius" description:"Remove segments whose points are all within a given radius from the starting point." default:"0"`
+	MinDistance      unit.Length   `long:"min-distance" description:"Remove segments that are shorter than the provided min distance." default:"0"`
+	MinDuration      time.Duration `long:"min-duration" description:"Remove segments that are shorter than the provided min duration." default:"0"`
+	MaxDuration      time.Duration `long:"max-duration" description:"Remove segments that are longer than the provided max duration." default:"0"`
+	Smooth           string        `long:"smooth" description:"Smooth point positions with the provided algorithm. 'kalman' applies a constant-velocity Kalman filter that uses timestamps and HDOP." choice:"kalman"`
+	SmoothRTS        bool          `long:"smooth-rts" description:"When smoothing with 'kalman', additionally apply a Rauch-Tung-Striebel backward pass, i.e., also use following points for smoothing."`
+	SmoothNoise      float64       `long:"smooth-noise" description:"When smoothing with 'kalman', the expected change of velocity in m/s². Larger values follow the recorded points more closely." default:"1"`
+	SmoothAccuracy   unit.Length   `long:"smooth-accuracy" description:"When smoothing with 'kalman', the expected position error in meters of a point with an HDOP of 1." default:"5"`
+	ResampleTime     time.Duration `long:"resample-time" description:"Resample segments to one point per provided duration, interpolating positions and elevations between the recorded points. Points are aligned to multiples of the duration, e.g., to full minutes for 1m." default:"0s"`
+	ResampleMaxGap   time.Duration `long:"resample-max-gap" description:"When resampling, do not interpolate between recorded points that are further apart than the provided duration." default:"0s"`
+	Densify          Length        `long:"densify" description:"Insert interpolated points wherever consecutive points are further apart than the provided distance, e.g., after signal loss in tunnels. Inserted points are marked with a 'synthetic' extension. Valid formats: 10; 10m; 1.5km." default:"0"`
+	ResampleDistance Length        `long:"resample-distance" description:"Resample segments to one point per provided distance along the path, interpolating timestamps and elevations between the recorded points. Valid formats: 10; 10m; 1.5km." default:"0"`
+	DEMDir           string        `long:"dem-dir" description:"Replace point elevations with elevations from the SRTM (.hgt) or GeoTIFF (.tif) tiles in the provided folder. Tiles must use WGS84 latitude / longitude." default:""`
+	DEMFillOnly      bool          `long:"dem-fill-only" description:"When using --dem-dir, only set elevations of points that have none."`
 }
 
 func (d DirectCommand) GetConfiguration() (tc config.TransformConfig, err error) {
@@ -26,9 +43,53 @@ func (d DirectCommand) GetConfiguration() (tc config.TransformConfig, err error)
 	}
 
 	directOptions := []options.DirectOptions{}
+	if d.Smooth == "kalman" {
+		if d.SmoothNoise <= 0 || d.SmoothAccuracy <= 0*unit.Metre {
+			err = CommandError{"smooth-noise and smooth-accuracy must be larger than 0"}
+			return
+		}
+		directOptions = append(directOptions, options.Kalman(d.SmoothNoise, d.SmoothAccuracy, d.SmoothRTS))
+	}
+	if 0 != len(d.DEMDir) {
+		var dem *gpxio.DEM
+		dem, err = gpxio.ReadDEM(d.DEMDir)
+		if err != nil {
+			return
+		}
+		directOptions = append(directOptions, options.ModelElevation(dem, d.DEMFillOnly))
+	}
+	elevationSmoothing, ok, err := d.ElevationSmoothingFlags.options()
+	if err != nil {
+		return
+	}
+	if ok {
+		directOptions = append(directOptions, elevationSmoothing)
+	}
+	if d.ResampleTime != defaultDuration {
+		directOptions = append(directOptions, options.TimeResample(d.ResampleTime, d.ResampleMaxGap))
+	}
+	if d.ResampleDistance != 0 {
+		directOptions = append(directOptions, options.DistanceResample(unit.Length(d.ResampleDistance)))
+	}
+	if d.Densify != 0 {
+		directOptions = append(directOptions, options.Densify(unit.Length(d.Densify)))
+	}
 	if d.Simplify != 0*unit.Metre {
 		directOptions = append(directOptions, options.DouglasPeucker(d.Simplify))
 	}
+	if d.SimplifyTime != 0*unit.Metre {
+		directOptions = append(directOptions, options.SynchronizedDouglasPeucker(d.SimplifyTime))
+	}
+	if d.SimplifyArea != 0 {
+		directOptions = append(directOptions, options.VisvalingamWhyatt(d.SimplifyArea))
+	}
+	if d.SimplifyPoints != 0 {
+		if d.SimplifyPoints < 2 {
+			err = CommandError{fmt.Sprintf("simplify-points must be at least 2, as the first and the last point are always kept; got %v", d.SimplifyPoints)}
+			return
+		}
+		directOptions = append(directOptions, options.SimplifyToPoints(d.SimplifyPoints))
+	}
 	if d.MinPoints != 0 {
 		directOptions = append(directOptions, options.MinPoints(d.MinPoints))
 	}
@@ -47,6 +108,17 @@ func (d DirectCommand) GetConfiguration() (tc config.TransformConfig, err error)
 	if d.RemoveStops {
 		directOptions = append(directOptions, options.RemoveStops())
 	}
+	timeCondition, ok, err := d.CalendarFlags.condition()
+	if err != nil {
+		return
+	}
+	if ok {
+		if d.DropTime {
+			directOptions = append(directOptions, options.DropSegmentTime(timeCondition))
+		} else {
+			directOptions = append(directOptions, options.KeepSegmentTime(timeCondition))
+		}
+	}
 	tc = config.NewTransformConfig(config.WithSegmentTransform(gpxtransform.Direct(directOptions...)))
 	return
 }
@@ -0,0 +1,38 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+)
+
+/*
+ElevationSmoothingFlags holds the arguments of commands that smooth elevations.
+*/
+type ElevationSmoothingFlags struct {
+	SmoothElevation       string `long:"smooth-elevation" description:"Smooth elevations with the provided filter. 'moving-average' averages all elevations within the window; 'savitzky-golay' fits a quadratic polynomial and flattens summits and valleys less." choice:"moving-average" choice:"savitzky-golay"`
+	SmoothElevationWindow int    `long:"smooth-elevation-window" description:"The odd number of points that are used for smoothing each elevation." default:"5"`
+}
+
+/*
+options returns the direct options for smoothing elevations. ok is false, if
+no smoothing was requested.
+*/
+func (e ElevationSmoothingFlags) options() (directOptions options.DirectOptions, ok bool, err error) {
+	if 0 == len(e.SmoothElevation) {
+		return
+	}
+	if e.SmoothElevationWindow < 1 || e.SmoothElevationWindow%2 == 0 {
+		err = CommandError{fmt.Sprintf("smooth-elevation-window must be odd and positive; got %v", e.SmoothElevationWindow)}
+		return
+	}
+	switch e.SmoothElevation {
+	case "moving-average":
+		return options.MovingAverageElevation(e.SmoothElevationWindow), true, nil
+	case "savitzky-golay":
+		return options.SavitzkyGolayElevation(e.SmoothElevationWindow), true, nil
+	default:
+		err = CommandError{fmt.Sprintf("unknown elevation filter: %v", e.SmoothElevation)}
+		return
+	}
+}
@@ -1,6 +1,11 @@
 package command
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abzicht/gpsplit/gpxio"
 	"github.com/abzicht/gpsplit/gpxtransform"
 	"github.com/abzicht/gpsplit/gpxtransform/config"
 	"github.com/abzicht/gpsplit/gpxtransform/options"
@@ -8,9 +13,22 @@ import (
 )
 
 type FilterCommand struct {
-	Trim      unit.Length `short:"t" long:"trim" description:"Trim all points at a segment's start AND end that are within the provided radius. If this command is used, --trim-start and --trim-end are ignored." default:"0"`
-	TrimStart unit.Length `long:"trim-start" description:"Trim all points at a segment's start that are within the provided radius." default:"0"`
-	TrimEnd   unit.Length `long:"trim-end" description:"Trim all points at a segment's end that are within the provided radius." default:"0"`
+	CalendarFlags
+	Trim              unit.Length `short:"t" long:"trim" description:"Trim all points at a segment's start AND end that are within the provided radius. If this command is used, --trim-start and --trim-end are ignored." default:"0"`
+	TrimStart         unit.Length `long:"trim-start" description:"Trim all points at a segment's start that are within the provided radius." default:"0"`
+	TrimEnd           unit.Length `long:"trim-end" description:"Trim all points at a segment's end that are within the provided radius." default:"0"`
+	MaxHdop           float64     `long:"max-hdop" description:"Remove all points whose horizontal dilution of precision (HDOP) is larger than the provided value. Points without HDOP are kept." default:"0"`
+	MaxVdop           float64     `long:"max-vdop" description:"Remove all points whose vertical dilution of precision (VDOP) is larger than the provided value. Points without VDOP are kept." default:"0"`
+	MaxPdop           float64     `long:"max-pdop" description:"Remove all points whose positional dilution of precision (PDOP) is larger than the provided value. Points without PDOP are kept." default:"0"`
+	MinSats           int         `long:"min-sats" description:"Remove all points that were recorded with less than the provided number of satellites. Points without satellite count are kept." default:"0"`
+	Fix               []string    `long:"fix" description:"Only keep points with the provided type of GPS fix (none, 2d, 3d, dgps, pps). Can be provided multiple times. Points without fix type are kept."`
+	KeepInside        string      `long:"keep-inside" description:"Only keep points that lie inside the provided area. The area is either a bounding box (MINLAT,MINLON,MAXLAT,MAXLON) or a GeoJSON / WKT file holding (multi) polygons." default:""`
+	DropInside        string      `long:"drop-inside" description:"Remove all points that lie inside the provided area. The area is either a bounding box (MINLAT,MINLON,MAXLAT,MAXLON) or a GeoJSON / WKT file holding (multi) polygons." default:""`
+	SplitArea         bool        `long:"split-area" description:"Split segments where the track leaves and re-enters the area provided with --keep-inside / --drop-inside, instead of connecting the remaining points. Parts that lie completely outside of the kept area (or inside of the dropped area) are removed."`
+	RemoveSynthetic   bool        `long:"remove-synthetic" description:"Remove all points that were inserted by gpsplit, e.g., by 'remove --densify'."`
+	PrivacyZone       []string    `long:"privacy-zone" description:"Remove all points within a circle and split segments where they enter and leave it. Format: LAT,LON,RADIUS with RADIUS in meters. Can be provided multiple times."`
+	PrivacyZoneFile   string      `long:"privacy-zone-file" description:"Read privacy zones (cf. --privacy-zone) from the provided file, one zone per line." default:""`
+	PrivacyZoneRandom unit.Length `long:"privacy-zone-random" description:"Enlarge every privacy zone by a random radius of up to the provided value and move its center randomly, such that the original center cannot be inferred." default:"0"`
 }
 
 func (f FilterCommand) GetConfiguration() (tc config.TransformConfig, err error) {
@@ -26,6 +44,143 @@ func (f FilterCommand) GetConfiguration() (tc config.TransformConfig, err error)
 			filterOptions = append(filterOptions, options.TrimEnd(f.TrimEnd))
 		}
 	}
+	if f.MaxHdop != 0 {
+		filterOptions = append(filterOptions, options.MaxHorizontalDilution(f.MaxHdop))
+	}
+	if f.MaxVdop != 0 {
+		filterOptions = append(filterOptions, options.MaxVerticalDilution(f.MaxVdop))
+	}
+	if f.MaxPdop != 0 {
+		filterOptions = append(filterOptions, options.MaxPositionalDilution(f.MaxPdop))
+	}
+	if f.MinSats != 0 {
+		filterOptions = append(filterOptions, options.MinSatellites(f.MinSats))
+	}
+	if len(f.Fix) != 0 {
+		for _, fix := range f.Fix {
+			switch strings.ToLower(fix) {
+			case "none", "2d", "3d", "dgps", "pps":
+			default:
+				err = CommandError{fmt.Sprintf("unknown fix type: %v; expecting one of none, 2d, 3d, dgps, pps", fix)}
+				return
+			}
+		}
+		filterOptions = append(filterOptions, options.FixType(f.Fix...))
+	}
+	timeCondition, ok, err := f.CalendarFlags.condition()
+	if err != nil {
+		return
+	}
+	if ok {
+		if f.DropTime {
+			filterOptions = append(filterOptions, options.DropTime(timeCondition))
+		} else {
+			filterOptions = append(filterOptions, options.KeepTime(timeCondition))
+		}
+	}
+	if f.RemoveSynthetic {
+		filterOptions = append(filterOptions, options.RemoveSynthetic())
+	}
+	// areas that segments are split at; their filters are applied after
+	// splitting, all other filters before
+	splitOptions := []options.SplitOptions{}
+	areaFilterOptions := []options.FilterOptions{}
+	if 0 != len(f.KeepInside) {
+		var area options.Area
+		area, err = parseArea(f.KeepInside)
+		if err != nil {
+			return
+		}
+		if f.SplitArea {
+			areaFilterOptions = append(areaFilterOptions, options.KeepInside(area))
+			splitOptions = append(splitOptions, options.AreaSplit(area))
+		} else {
+			filterOptions = append(filterOptions, options.KeepInside(area))
+		}
+	}
+	if 0 != len(f.DropInside) {
+		var area options.Area
+		area, err = parseArea(f.DropInside)
+		if err != nil {
+			return
+		}
+		if f.SplitArea {
+			areaFilterOptions = append(areaFilterOptions, options.DropInside(area))
+			splitOptions = append(splitOptions, options.AreaSplit(area))
+		} else {
+			filterOptions = append(filterOptions, options.DropInside(area))
+		}
+	}
+	zones, err := f.privacyZones()
+	if err != nil {
+		return
+	}
+	if 0 != len(zones) {
+		areaFilterOptions = append(areaFilterOptions, options.DropInside(zones))
+		splitOptions = append(splitOptions, options.AreaSplit(zones))
+	}
+	if 0 != len(splitOptions) {
+		// filters relative to a segment's start and end, e.g., --trim, refer
+		// to the original segment and are hence applied before splitting
+		tc = config.NewTransformConfig(config.WithSegmentTransform(gpxtransform.Chain(
+			gpxtransform.Filter(filterOptions...),
+			gpxtransform.FilterParts(splitOptions, areaFilterOptions...),
+		)))
+		return
+	}
 	tc = config.NewTransformConfig(config.WithSegmentTransform(gpxtransform.Filter(filterOptions...)))
 	return
 }
+
+/*
+privacyZones collects all privacy zones that are provided on the command line
+or in a file.
+*/
+func (f FilterCommand) privacyZones() (zones options.MultiArea, err error) {
+	circles := []options.Circle{}
+	for _, zone := range f.PrivacyZone {
+		var circle options.Circle
+		circle, err = gpxio.ParseCircle(zone)
+		if err != nil {
+			return
+		}
+		circles = append(circles, circle)
+	}
+	if 0 != len(f.PrivacyZoneFile) {
+		var fileCircles []options.Circle
+		fileCircles, err = gpxio.ReadCircles(f.PrivacyZoneFile)
+		if err != nil {
+			return
+		}
+		circles = append(circles, fileCircles...)
+	}
+	zones = options.MultiArea{}
+	for _, circle := range circles {
+		if f.PrivacyZoneRandom != 0*unit.Metre {
+			circle = circle.Randomize(f.PrivacyZoneRandom)
+		}
+		zones = append(zones, circle)
+	}
+	return
+}
+
+/*
+parseArea parses either a bounding box (MINLAT,MINLON,MAXLAT,MAXLON) or reads
+the area from the file that value points to.
+*/
+func parseArea(value string) (area options.Area, err error) {
+	bounds := strings.Split(value, ",")
+	if 4 != len(bounds) {
+		return gpxio.ReadArea(value)
+	}
+	values := make([]float64, len(bounds))
+	for i, bound := range bounds {
+		values[i], err = strconv.ParseFloat(strings.TrimSpace(bound), 64)
+		if err != nil {
+			// not a bounding box, so we expect a file
+			return gpxio.ReadArea(value)
+		}
+	}
+	area = options.BoundingBox{MinLatitude: values[0], MinLongitude: values[1], MaxLatitude: values[2], MaxLongitude: values[3]}
+	return
+}
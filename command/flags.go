@@ -13,14 +13,18 @@ import (
 Flag holds all arguments passed via command line
 */
 type Flags struct {
-	In      string         `short:"i" long:"in" description:"The file or folder that new GPX data is read from. Leave empty to read from STDIN."`
-	Out     string         `short:"o" long:"out" description:"The file or folder that new GPX data is written to. Leave empty to write to STDOUT."`
-	Verbose []bool         `short:"v" long:"verbosity" description:"Verbosity with that information is logged to STDERR."`
-	Split   SplitCommand   `command:"split" description:"Splits track segments into multiple tracks or files."`
-	Merge   MergeCommand   `command:"merge" description:"Merges multiple files / tracks / track segments into single instances."`
-	Filter  FilterCommand  `command:"filter" description:"Applies filters on waypoints."`
-	Remove  DirectCommand  `command:"remove" description:"Removes certain tracks / track segments / waypoints."`
-	Analyze AnalyzeCommand `command:"analyze" description:"Prints information for the provided GPX data."`
+	In       string          `short:"i" long:"in" description:"The file or folder that new GPX data is read from. Leave empty to read from STDIN."`
+	Out      string          `short:"o" long:"out" description:"The file or folder that new GPX data is written to. Leave empty to write to STDOUT."`
+	Verbose  []bool          `short:"v" long:"verbosity" description:"Verbosity with that information is logged to STDERR."`
+	Split    SplitCommand    `command:"split" description:"Splits track segments into multiple tracks or files."`
+	Merge    MergeCommand    `command:"merge" description:"Merges multiple files / tracks / track segments into single instances."`
+	Filter   FilterCommand   `command:"filter" description:"Applies filters on waypoints."`
+	Remove   DirectCommand   `command:"remove" description:"Removes certain tracks / track segments / waypoints."`
+	Analyze  AnalyzeCommand  `command:"analyze" description:"Prints information for the provided GPX data."`
+	Sanitize SanitizeCommand `command:"sanitize" description:"Removes personal information, e.g., before publishing files."`
+	Time     TimeCommand     `command:"time" description:"Corrects timestamps, e.g., shifted clocks, wrong time zones, clock drift, and GPS week rollovers."`
+	Reverse  ReverseCommand  `command:"reverse" description:"Reverses the direction of paths, e.g., to turn an outbound trip into a return route."`
+	Stays    StaysCommand    `command:"stays" description:"Adds a waypoint for every place where paths stayed for some time, e.g., to list the places visited on a trip."`
 }
 
 /*
@@ -37,7 +41,7 @@ func (flagOpts Flags) Init() (err error) {
 
 /*
 GetConfiguration returns the configuration for the sub-command specified with name.
-Valid command names are split, filter, direct, and analyze.
+Valid command names are split, merge, filter, remove, analyze, time, sanitize, reverse, and stays.
 */
 func (flagOpts Flags) GetConfiguration(name string) (tc config.TransformConfig, err error) {
 	switch name {
@@ -47,10 +51,18 @@ func (flagOpts Flags) GetConfiguration(name string) (tc config.TransformConfig,
 		tc, err = flagOpts.Merge.GetConfiguration()
 	case "filter":
 		tc, err = flagOpts.Filter.GetConfiguration()
-	case "direct":
+	case "remove":
 		tc, err = flagOpts.Remove.GetConfiguration()
 	case "analyze":
 		tc, err = flagOpts.Analyze.GetConfiguration()
+	case "time":
+		tc, err = flagOpts.Time.GetConfiguration()
+	case "sanitize":
+		tc, err = flagOpts.Sanitize.GetConfiguration()
+	case "reverse":
+		tc, err = flagOpts.Reverse.GetConfiguration()
+	case "stays":
+		tc, err = flagOpts.Stays.GetConfiguration()
 	default:
 		err = errors.New(fmt.Sprintf("unknown command: %v", name))
 		return
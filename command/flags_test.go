@@ -0,0 +1,25 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConfigurationKnowsAllCommands(t *testing.T) {
+	flagsType := reflect.TypeOf(Flags{})
+	for i := 0; i < flagsType.NumField(); i++ {
+		name, ok := flagsType.Field(i).Tag.Lookup("command")
+		if !ok {
+			continue
+		}
+		_, err := Flags{}.GetConfiguration(name)
+		if err != nil {
+			// commands may reject their default arguments, but must be known
+			assert.NotContains(t, err.Error(), "unknown command", name)
+		}
+	}
+	_, err := Flags{}.GetConfiguration("direct")
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+Length is a command line argument for distances. Other than unit.Length, it
+accepts the suffixes "m" and "km", e.g., 10m or 1.5km. Values without suffix
+are interpreted as meters.
+*/
+type Length unit.Length
+
+func (l *Length) UnmarshalFlag(value string) error {
+	factor := unit.Metre
+	number := strings.TrimSpace(value)
+	if strings.HasSuffix(number, "km") {
+		factor = unit.Kilo * unit.Metre
+		number = strings.TrimSuffix(number, "km")
+	} else if strings.HasSuffix(number, "m") {
+		number = strings.TrimSuffix(number, "m")
+	}
+	length, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return CommandError{fmt.Sprintf("incorrect format for length: %v; valid formats: 10; 10m; 1.5km", value)}
+	}
+	*l = Length(unit.Length(length) * factor)
+	return nil
+}
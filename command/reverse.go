@@ -0,0 +1,24 @@
+package command
+
+import (
+	"github.com/abzicht/gpsplit/gpxtransform"
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+)
+
+type ReverseCommand struct {
+	MirrorTime bool `short:"t" long:"mirror-time" description:"Mirror timestamps, so that time still increases along the reversed path."`
+	PointsOnly bool `short:"p" long:"points-only" description:"Only reverse the points of every segment, but keep the order of segments and tracks. Combined with --mirror-time, timestamps are mirrored within every segment."`
+}
+
+func (r ReverseCommand) GetConfiguration() (tc config.TransformConfig, err error) {
+	if r.PointsOnly {
+		tc = config.NewTransformConfig(config.WithSegmentTransform(gpxtransform.ReverseSegment(r.MirrorTime)))
+		return
+	}
+	tc = config.NewTransformConfig(
+		config.WithSegmentTransform(gpxtransform.ReverseSegment(false)),
+		config.WithTrackTransform(gpxtransform.ReverseTrack()),
+		config.WithFileTransform(gpxtransform.ReverseFile(r.MirrorTime)),
+	)
+	return
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform"
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+)
+
+type SanitizeCommand struct {
+	StripMetadata   bool   `short:"m" long:"strip-metadata" description:"Remove creator, author, links, keywords, and time of files as well as the source (device) of tracks, routes, and points."`
+	StripExtensions bool   `short:"e" long:"strip-extensions" description:"Remove all extensions, e.g., heart rate, cadence, and temperature recorded by sensors."`
+	Decimals        int    `short:"d" long:"decimals" description:"Round coordinates to the provided number of decimals. 5 decimals correspond to about 1 meter, 4 decimals to about 10 meters. Negative values disable rounding." default:"-1"`
+	RelativeTime    string `long:"relative-time" description:"Shift all timestamps of a file, such that it starts at the provided epoch (in UTC). Durations and speeds are kept. Valid formats: 1970-01-01; 2000-01-01T00:00:00." default:""`
+}
+
+func (s SanitizeCommand) GetConfiguration() (tc config.TransformConfig, err error) {
+	sanitizeOptions := []options.SanitizeOptions{}
+	if s.StripMetadata {
+		sanitizeOptions = append(sanitizeOptions, options.StripMetadata())
+	}
+	if s.StripExtensions {
+		sanitizeOptions = append(sanitizeOptions, options.StripExtensions())
+	}
+	if s.Decimals >= 0 {
+		sanitizeOptions = append(sanitizeOptions, options.RoundCoordinates(s.Decimals))
+	}
+	if 0 != len(s.RelativeTime) {
+		var epoch time.Time
+		epoch, _, err = parseCalendarTime(s.RelativeTime, time.UTC)
+		if err != nil {
+			return
+		}
+		sanitizeOptions = append(sanitizeOptions, options.RelativeTime(epoch))
+	}
+	if 0 == len(sanitizeOptions) {
+		err = CommandError{"sanitize requires at least one of --strip-metadata, --strip-extensions, --decimals, and --relative-time"}
+		return
+	}
+	tc = config.NewTransformConfig(config.WithFileTransform(gpxtransform.Sanitize(sanitizeOptions...)))
+	return
+}
@@ -1,22 +1,48 @@
 package command
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/abzicht/gogenericfunc/fun"
+	"github.com/abzicht/gpsplit/gpxio"
 	"github.com/abzicht/gpsplit/gpxtransform"
 	"github.com/abzicht/gpsplit/gpxtransform/config"
 	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/tkrajina/gpxgo/gpx"
 	"gonum.org/v1/gonum/unit"
 )
 
 type SplitCommand struct {
-	Tracks     bool          `short:"t" long:"tracks" description:"Create an individual file for every track."`
-	Segments   bool          `short:"s" long:"segments" description:"Create an individual track for every track segment."`
-	Distance   unit.Length   `short:"d" long:"distance" description:"If the distance between two consecutive points is larger than this value (in meters), the path is split between those points." default:"0"`
-	Duration   time.Duration `short:"u" long:"duration" description:"If the time between two consecutive points is larger than this duration, the path is split between those points. Valid formats: 5m (5 minutes); 12h (12 hours); 4h12m0s (4 hours 12 minutes 0 seconds)." default:"0s"`
-	PauseSplit string        `long:"pause-split" description:"Split paths, if no movement over a longer period is detected, i.e., if points lie within a provided radius for a given minimal time. Time and radius are comma-separated in strict order (RADIUS,TIME). Valid formats: 20,5m (20 meters, 5 minutes); 1000,12h (1 kilometer, 12 hours); 300,4h12m0s (300 meters, 4 hours 12 minutes 0 seconds)." default:""`
+	Tracks              bool          `short:"t" long:"tracks" description:"Create an individual file for every track."`
+	Segments            bool          `short:"s" long:"segments" description:"Create an individual track for every track segment."`
+	Distance            unit.Length   `short:"d" long:"distance" description:"If the distance between two consecutive points is larger than this value (in meters), the path is split between those points." default:"0"`
+	Duration            time.Duration `short:"u" long:"duration" description:"If the time between two consecutive points is larger than this duration, the path is split between those points. Valid formats: 5m (5 minutes); 12h (12 hours); 4h12m0s (4 hours 12 minutes 0 seconds)." default:"0s"`
+	EveryDistance       Length        `long:"every-distance" description:"Split paths whenever the distance travelled since the start of the segment passes a multiple of this value, e.g., for laps or stages of equal length. Valid formats: 500 (500 meters); 500m; 50km." default:"0"`
+	EveryDuration       time.Duration `long:"every-duration" description:"Split paths whenever the time travelled since the start of the segment passes a multiple of this duration. Valid formats: 30m; 1h; 1h30m." default:"0s"`
+	MovingTime          bool          `long:"moving-time" description:"Only count the time in which the speed exceeded 1 km/h for --every-duration."`
+	Turnaround          bool          `long:"turnaround" description:"Divide out-and-back paths at their turnaround point, i.e., at the point farthest from the start, into an outbound and a return leg. Both legs share the turnaround point. Paths whose return leg does not follow the outbound leg are not divided."`
+	TurnaroundTolerance Length        `long:"turnaround-tolerance" description:"The maximal distance between the return leg and the outbound leg of out-and-back paths (cf. --turnaround). Valid formats: 30 (30 meters); 30m; 0.1km." default:"30"`
+	Climbs              bool          `long:"climbs" description:"Divide paths at their summits and valleys into climbs and descents. Summits and valleys must stand out by at least --prominence. Consecutive parts share their summit or valley."`
+	Prominence          Length        `long:"prominence" description:"The elevation difference that summits and valleys must at least have to both neighboring valleys and summits (cf. --climbs). Valid formats: 30 (30 meters); 30m; 0.05km." default:"30"`
+	Parts               int           `long:"parts" description:"Divide every path into the provided number of parts of equal size (cf. --by). Consecutive parts share their boundary point." default:"0"`
+	By                  string        `long:"by" description:"How the size of parts is measured for --parts." choice:"distance" choice:"duration" choice:"points" default:"distance"`
+	AtWaypoints         string        `long:"at-waypoints" description:"Split paths whenever they pass within --radius of a waypoint of the provided GPX file, e.g., bus or train stops. Paths are split after the point closest to the waypoint." default:""`
+	At                  []string      `long:"at" description:"Split paths whenever they pass within --radius of the provided location (LAT,LON). Can be provided multiple times. Valid formats: 50.1109,8.6821."`
+	Laps                bool          `long:"laps" description:"Split paths into laps whenever they return within --radius of their first point (or of --lap-start) heading in the same direction."`
+	LapStart            string        `long:"lap-start" description:"The reference point (LAT,LON) of --laps. Defaults to the first point of every path. Valid formats: 50.1109,8.6821." default:""`
+	LapLine             string        `long:"lap-line" description:"Split paths into laps whenever they cross the provided start/finish line (LAT,LON,LAT,LON) in the same direction as the first time. Valid formats: 50.1109,8.6821,50.1111,8.6823." default:""`
+	Radius              Length        `long:"radius" description:"The radius around locations of --at-waypoints, --at, and --laps. Valid formats: 50 (50 meters); 50m; 0.2km." default:"50"`
+	ByMode              bool          `long:"by-mode" description:"Split paths whenever the mode of transport (stationary, walking, cycling, motorized) changes. Modes are classified from speeds and accelerations within --mode-window."`
+	TagMode             bool          `long:"tag-mode" description:"Set the type of every track to the mode of transport in which most of its time was spent. Combine with --by-mode and --segments to tag every part of a path."`
+	ModeWindow          time.Duration `long:"mode-window" description:"The time window around every point that is used for classifying modes of transport. Modes that last shorter are merged into their neighbors." default:"2m"`
+	ByDay               bool          `long:"by-day" description:"Split paths between points that were recorded on different days in the time zone --tz."`
+	ByWeek              bool          `long:"by-week" description:"Split paths between points that were recorded in different weeks (starting on Monday) in the time zone --tz."`
+	ByMonth             bool          `long:"by-month" description:"Split paths between points that were recorded in different months in the time zone --tz."`
+	TimeZone            string        `long:"tz" description:"The IANA time zone that --by-day, --by-week, and --by-month refer to, e.g., Europe/Berlin." default:"UTC"`
+	PauseSplit          string        `long:"pause-split" description:"Split paths, if no movement over a longer period is detected, i.e., if points lie within a provided radius for a given minimal time. Time and radius are comma-separated in strict order (RADIUS,TIME). Valid formats: 20,5m (20 meters, 5 minutes); 1000,12h (1 kilometer, 12 hours); 300,4h12m0s (300 meters, 4 hours 12 minutes 0 seconds)." default:""`
 }
 
 func (s SplitCommand) GetConfiguration() (tc config.TransformConfig, err error) {
@@ -53,12 +79,154 @@ func (s SplitCommand) GetConfiguration() (tc config.TransformConfig, err error)
 		}
 		splitOptions = append(splitOptions, options.PauseSplit(unit.Length(radiusInt)*unit.Metre, duration))
 	}
-	tc = config.NewTransformConfig(config.WithSegmentTransform(gpxtransform.Split(splitOptions...)))
+	// CumulativeDistanceSplit
+	if s.EveryDistance != 0 {
+		splitOptions = append(splitOptions, options.CumulativeDistanceSplit(unit.Length(s.EveryDistance)))
+	}
+	// CumulativeDurationSplit
+	if s.EveryDuration != 0 {
+		splitOptions = append(splitOptions, options.CumulativeDurationSplit(s.EveryDuration, s.MovingTime))
+	}
+	// ProximitySplit
+	if 0 != len(s.AtWaypoints) || 0 != len(s.At) {
+		var locations []gpx.Point
+		locations, err = s.locations()
+		if err != nil {
+			return
+		}
+		splitOptions = append(splitOptions, options.ProximitySplit(locations, unit.Length(s.Radius)))
+	}
+	// LapSplit
+	if s.Laps {
+		reference := fun.Option[gpx.Point](fun.NewNone[gpx.Point]())
+		if 0 != len(s.LapStart) {
+			var points []gpx.Point
+			points, err = parsePoints(s.LapStart, 1)
+			if err != nil {
+				return
+			}
+			reference = fun.NewSome(points[0])
+		}
+		splitOptions = append(splitOptions, options.LapSplit(reference, unit.Length(s.Radius)))
+	}
+	// LineSplit
+	if 0 != len(s.LapLine) {
+		var points []gpx.Point
+		points, err = parsePoints(s.LapLine, 2)
+		if err != nil {
+			return
+		}
+		splitOptions = append(splitOptions, options.LineSplit(points[0], points[1]))
+	}
+	// ModeSplit
+	if s.ByMode {
+		splitOptions = append(splitOptions, options.ModeSplit(s.ModeWindow))
+	}
+	// CalendarSplit
+	if s.ByDay || s.ByWeek || s.ByMonth {
+		var location *time.Location
+		location, err = time.LoadLocation(s.TimeZone)
+		if err != nil {
+			return
+		}
+		if s.ByDay {
+			splitOptions = append(splitOptions, options.CalendarSplit(location, options.Day))
+		}
+		if s.ByWeek {
+			splitOptions = append(splitOptions, options.CalendarSplit(location, options.Week))
+		}
+		if s.ByMonth {
+			splitOptions = append(splitOptions, options.CalendarSplit(location, options.Month))
+		}
+	}
+	segmentTransforms := []config.GPXSegmentTransform{gpxtransform.Split(splitOptions...)}
+	// TurnaroundSplit
+	if s.Turnaround {
+		segmentTransforms = append(segmentTransforms, gpxtransform.Direct(options.TurnaroundSplit(unit.Length(s.TurnaroundTolerance))))
+	}
+	// ClimbSplit
+	if s.Climbs {
+		segmentTransforms = append(segmentTransforms, gpxtransform.Direct(options.ClimbSplit(unit.Length(s.Prominence))))
+	}
+	// EqualParts
+	if s.Parts != 0 {
+		if s.Parts < 0 {
+			err = CommandError{fmt.Sprintf("parts must be positive; got %v", s.Parts)}
+			return
+		}
+		measures := map[string]options.Measure{
+			"distance": options.MeasureDistance,
+			"duration": options.MeasureDuration,
+			"points":   options.MeasurePoints,
+		}
+		segmentTransforms = append(segmentTransforms, gpxtransform.Direct(options.EqualParts(s.Parts, measures[s.By])))
+	}
+	tc = config.NewTransformConfig(config.WithSegmentTransform(gpxtransform.Chain(segmentTransforms...)))
 	if s.Tracks {
 		tc = config.WithFileTransform(gpxtransform.SplitFileByTrack())(tc)
 	}
+	trackTransforms := []config.GPXTrackTransform{}
 	if s.Segments {
-		tc = config.WithTrackTransform(gpxtransform.SplitTrackBySegment())(tc)
+		trackTransforms = append(trackTransforms, gpxtransform.SplitTrackBySegment())
+	}
+	if s.TagMode {
+		trackTransforms = append(trackTransforms, gpxtransform.TagMode(s.ModeWindow))
+	}
+	if 0 != len(trackTransforms) {
+		tc = config.WithTrackTransform(gpxtransform.ChainTrack(trackTransforms...))(tc)
+	}
+	return
+}
+
+/*
+locations returns the locations of --at-waypoints and --at.
+*/
+func (s SplitCommand) locations() (locations []gpx.Point, err error) {
+	locations = []gpx.Point{}
+	if 0 != len(s.AtWaypoints) {
+		var gpxFile gpx.GPX
+		gpxFile, err = gpxio.ReadFile(s.AtWaypoints)
+		if err != nil {
+			return
+		}
+		if 0 == len(gpxFile.Waypoints) {
+			err = CommandError{fmt.Sprintf("no waypoints found in %v", s.AtWaypoints)}
+			return
+		}
+		for _, waypoint := range gpxFile.Waypoints {
+			locations = append(locations, waypoint.Point)
+		}
+	}
+	for _, location := range s.At {
+		var points []gpx.Point
+		points, err = parsePoints(location, 1)
+		if err != nil {
+			return
+		}
+		locations = append(locations, points...)
+	}
+	return
+}
+
+/*
+parsePoints parses count comma-separated points (LAT,LON,LAT,LON,...).
+*/
+func parsePoints(value string, count int) (points []gpx.Point, err error) {
+	coordinates := strings.Split(value, ",")
+	if 2*count != len(coordinates) {
+		err = CommandError{fmt.Sprintf("incorrect format for %v; expecting %v comma-separated coordinates (LAT,LON,...)", value, 2*count)}
+		return
+	}
+	points = make([]gpx.Point, count)
+	for i := 0; i < count; i++ {
+		points[i].Latitude, err = strconv.ParseFloat(strings.TrimSpace(coordinates[2*i]), 64)
+		if err == nil {
+			points[i].Longitude, err = strconv.ParseFloat(strings.TrimSpace(coordinates[2*i+1]), 64)
+		}
+		if err != nil {
+			err = CommandError{fmt.Sprintf("incorrect format for coordinates: %v", value)}
+			return
+		}
 	}
 	return
 }
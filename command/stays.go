@@ -0,0 +1,29 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform"
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"gonum.org/v1/gonum/unit"
+)
+
+type StaysCommand struct {
+	Radius   Length        `short:"r" long:"radius" description:"The radius that all points of a stay lie within. Valid formats: 50 (50 meters); 50m; 0.2km." default:"50"`
+	Duration time.Duration `short:"d" long:"duration" description:"The minimal duration of a stay. Valid formats: 5m (5 minutes); 1h; 1h30m." default:"5m"`
+	Remove   bool          `long:"remove" description:"Remove the points of all stays from tracks, keeping only the waypoints. Segments are split where stays were removed."`
+}
+
+func (s StaysCommand) GetConfiguration() (tc config.TransformConfig, err error) {
+	if s.Radius <= 0 {
+		err = CommandError{fmt.Sprintf("radius must be positive; got %v", s.Radius)}
+		return
+	}
+	if s.Duration <= 0 {
+		err = CommandError{fmt.Sprintf("duration must be positive; got %v", s.Duration)}
+		return
+	}
+	tc = config.NewTransformConfig(config.WithFileTransform(gpxtransform.ExtractStays(unit.Length(s.Radius), s.Duration, s.Remove)))
+	return
+}
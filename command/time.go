@@ -0,0 +1,91 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform"
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+)
+
+type TimeCommand struct {
+	RolloverRepair string        `long:"rollover-repair" description:"Repair timestamps that are shifted by multiples of 1024 weeks due to the GPS week number rollover bug (e.g., recordings dated 2002 or 2039). Timestamps are moved as close as possible to the provided reference, which is either a date near the recording or a file whose modification time is used. Valid formats: 2024-05-01; ./recording.gpx." default:""`
+	ReinterpretTZ  string        `long:"reinterpret-tz" description:"Interpret timestamps as local time of the provided IANA time zone (e.g., Europe/Berlin) and convert them to UTC. Use this for devices that record local time, but label it as UTC." default:""`
+	Drift          []string      `long:"drift" description:"Correct clock drift with two reference points RECORDED=ACTUAL, where RECORDED is a timestamp as written in the file (after --rollover-repair) and ACTUAL is the true time at that moment (both in UTC). Timestamps are scaled linearly between the references. Must be provided exactly twice. Valid formats: 2024-05-01T07:30:00=2024-05-01T07:30:12."`
+	Shift          time.Duration `long:"shift" description:"Shift all timestamps by the provided duration. Valid formats: 5m (5 minutes later); -1h30m (1 hour 30 minutes earlier)." default:"0s"`
+}
+
+/*
+GetConfiguration returns a file transform that corrects all timestamps. The
+week number rollover is repaired first, followed by drift, time zone, and shift.
+*/
+func (t TimeCommand) GetConfiguration() (tc config.TransformConfig, err error) {
+	timeMaps := []options.TimeMap{}
+	if 0 != len(t.RolloverRepair) {
+		var reference time.Time
+		reference, err = rolloverReference(t.RolloverRepair)
+		if err != nil {
+			return
+		}
+		timeMaps = append(timeMaps, options.WeekRollover(reference))
+	}
+	if 0 != len(t.Drift) {
+		if 2 != len(t.Drift) {
+			err = CommandError{fmt.Sprintf("drift requires exactly two reference points; got %v", len(t.Drift))}
+			return
+		}
+		references := [2][2]time.Time{}
+		for i, reference := range t.Drift {
+			recordedAndActual := strings.Split(reference, "=")
+			if 2 != len(recordedAndActual) {
+				err = CommandError{"incorrect format for drift; expecting format RECORDED=ACTUAL"}
+				return
+			}
+			for j, value := range recordedAndActual {
+				references[i][j], _, err = parseCalendarTime(strings.TrimSpace(value), time.UTC)
+				if err != nil {
+					return
+				}
+			}
+		}
+		var drift options.TimeMap
+		drift, err = options.DriftCorrection(references[0][0], references[0][1], references[1][0], references[1][1])
+		if err != nil {
+			return
+		}
+		timeMaps = append(timeMaps, drift)
+	}
+	if 0 != len(t.ReinterpretTZ) {
+		var location *time.Location
+		location, err = time.LoadLocation(t.ReinterpretTZ)
+		if err != nil {
+			return
+		}
+		timeMaps = append(timeMaps, options.ReinterpretTimeZone(location))
+	}
+	if t.Shift != 0 {
+		timeMaps = append(timeMaps, options.ShiftTime(t.Shift))
+	}
+	tc = config.NewTransformConfig(config.WithFileTransform(gpxtransform.CorrectTime(timeMaps...)))
+	return
+}
+
+/*
+rolloverReference parses value as a date or, if this fails, returns the
+modification time of the file that value refers to.
+*/
+func rolloverReference(value string) (reference time.Time, err error) {
+	reference, _, err = parseCalendarTime(value, time.UTC)
+	if err == nil {
+		return
+	}
+	info, err := os.Stat(value)
+	if err != nil {
+		err = CommandError{fmt.Sprintf("rollover-repair requires a date or an existing file; got %v", value)}
+		return
+	}
+	return info.ModTime(), nil
+}
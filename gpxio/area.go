@@ -0,0 +1,301 @@
+package gpxio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+ReadArea reads an area from the file identified with the provided fileName.
+The file must either contain GeoJSON (Polygon, MultiPolygon, Feature, or
+FeatureCollection) or WKT (POLYGON or MULTIPOLYGON).
+*/
+func ReadArea(fileName string) (area options.Area, err error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		err = errors.Join(errors.New(fmt.Sprintf("could not read area file %v", fileName)), err)
+		return
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		return ParseGeoJSON(data)
+	}
+	return ParseWKT(string(data))
+}
+
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Geometries  []geoJSON       `json:"geometries"`
+	Features    []geoJSON       `json:"features"`
+}
+
+/*
+ParseGeoJSON parses a GeoJSON object into an area. Only polygonal geometries
+are considered; all other geometries result in an error.
+*/
+func ParseGeoJSON(data []byte) (area options.Area, err error) {
+	var object geoJSON
+	err = json.Unmarshal(data, &object)
+	if err != nil {
+		err = errors.Join(errors.New("could not parse GeoJSON"), err)
+		return
+	}
+	return object.toArea()
+}
+
+func (g geoJSON) toArea() (area options.Area, err error) {
+	switch g.Type {
+	case "FeatureCollection":
+		return geoJSONCollection(g.Features)
+	case "GeometryCollection":
+		return geoJSONCollection(g.Geometries)
+	case "Feature":
+		if g.Geometry == nil {
+			err = errors.New("GeoJSON feature has no geometry")
+			return
+		}
+		return g.Geometry.toArea()
+	case "Polygon":
+		var rings [][][]float64
+		err = json.Unmarshal(g.Coordinates, &rings)
+		if err != nil {
+			return
+		}
+		return geoJSONPolygon(rings)
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		err = json.Unmarshal(g.Coordinates, &polygons)
+		if err != nil {
+			return
+		}
+		multiArea := options.MultiArea{}
+		for _, rings := range polygons {
+			var polygon options.Polygon
+			polygon, err = geoJSONPolygon(rings)
+			if err != nil {
+				return
+			}
+			multiArea = append(multiArea, polygon)
+		}
+		return multiArea, nil
+	default:
+		err = errors.New(fmt.Sprintf("unsupported GeoJSON type: %v", g.Type))
+		return
+	}
+}
+
+func geoJSONCollection(objects []geoJSON) (area options.Area, err error) {
+	multiArea := options.MultiArea{}
+	for _, object := range objects {
+		var a options.Area
+		a, err = object.toArea()
+		if err != nil {
+			return
+		}
+		multiArea = append(multiArea, a)
+	}
+	return multiArea, nil
+}
+
+func geoJSONPolygon(rings [][][]float64) (polygon options.Polygon, err error) {
+	for _, ring := range rings {
+		points := []gpx.Point{}
+		for _, position := range ring {
+			if len(position) < 2 {
+				err = errors.New("GeoJSON position requires longitude and latitude")
+				return
+			}
+			// GeoJSON positions are ordered as longitude, latitude
+			points = append(points, gpx.Point{Latitude: position[1], Longitude: position[0]})
+		}
+		polygon.Rings = append(polygon.Rings, points)
+	}
+	return
+}
+
+/*
+ParseWKT parses a WKT string (POLYGON or MULTIPOLYGON) into an area.
+*/
+func ParseWKT(wkt string) (area options.Area, err error) {
+	wkt = strings.TrimSpace(wkt)
+	bracket := strings.Index(wkt, "(")
+	if bracket == -1 {
+		err = errors.New("could not parse WKT: missing coordinates")
+		return
+	}
+	// ignore dimension suffixes, e.g., "POLYGON Z"
+	geometryType := ""
+	if fields := strings.Fields(wkt[:bracket]); len(fields) != 0 {
+		geometryType = strings.ToUpper(fields[0])
+	}
+	node, rest, err := parseWKTList(wkt[bracket:])
+	if err != nil {
+		return
+	}
+	if len(strings.TrimSpace(rest)) != 0 {
+		err = errors.New("could not parse WKT: unexpected trailing characters")
+		return
+	}
+	switch geometryType {
+	case "POLYGON":
+		return wktPolygon(node)
+	case "MULTIPOLYGON":
+		multiArea := options.MultiArea{}
+		for _, child := range node.children {
+			var polygon options.Polygon
+			polygon, err = wktPolygon(child)
+			if err != nil {
+				return
+			}
+			multiArea = append(multiArea, polygon)
+		}
+		return multiArea, nil
+	default:
+		err = errors.New(fmt.Sprintf("unsupported WKT type: %v", geometryType))
+		return
+	}
+}
+
+/*
+wktList is a parenthesized WKT list that either holds further lists
+(children) or a comma-separated sequence of coordinates (points).
+*/
+type wktList struct {
+	children []wktList
+	points   []gpx.Point
+}
+
+/*
+parseWKTList parses the parenthesized list at the start of s and returns the
+remainder of s.
+*/
+func parseWKTList(s string) (list wktList, rest string, err error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "(") {
+		err = errors.New("could not parse WKT: expecting \"(\"")
+		return
+	}
+	s = strings.TrimSpace(s[1:])
+	if strings.HasPrefix(s, "(") {
+		for {
+			var child wktList
+			child, s, err = parseWKTList(s)
+			if err != nil {
+				return
+			}
+			list.children = append(list.children, child)
+			s = strings.TrimSpace(s)
+			if strings.HasPrefix(s, ",") {
+				s = s[1:]
+				continue
+			}
+			break
+		}
+	} else {
+		end := strings.Index(s, ")")
+		if end == -1 {
+			err = errors.New("could not parse WKT: expecting \")\"")
+			return
+		}
+		for _, coordinates := range strings.Split(s[:end], ",") {
+			fields := strings.Fields(coordinates)
+			if len(fields) < 2 {
+				err = errors.New(fmt.Sprintf("could not parse WKT coordinates: \"%v\"", coordinates))
+				return
+			}
+			var lon, lat float64
+			lon, err = strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return
+			}
+			lat, err = strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return
+			}
+			list.points = append(list.points, gpx.Point{Latitude: lat, Longitude: lon})
+		}
+		s = s[end:]
+	}
+	if !strings.HasPrefix(s, ")") {
+		err = errors.New("could not parse WKT: expecting \")\"")
+		return
+	}
+	return list, s[1:], nil
+}
+
+func wktPolygon(list wktList) (polygon options.Polygon, err error) {
+	if len(list.children) == 0 {
+		err = errors.New("could not parse WKT: polygon without rings")
+		return
+	}
+	for _, ring := range list.children {
+		if len(ring.children) != 0 {
+			err = errors.New("could not parse WKT: unexpected nesting in polygon")
+			return
+		}
+		polygon.Rings = append(polygon.Rings, ring.points)
+	}
+	return
+}
+
+/*
+ParseCircle parses a circle from the format LAT,LON,RADIUS, where RADIUS is
+given in meters.
+*/
+func ParseCircle(value string) (circle options.Circle, err error) {
+	fields := strings.Split(value, ",")
+	if 3 != len(fields) {
+		err = errors.New(fmt.Sprintf("incorrect format for circle \"%v\"; expecting format LAT,LON,RADIUS", value))
+		return
+	}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		values[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			err = errors.Join(errors.New(fmt.Sprintf("incorrect format for circle \"%v\"", value)), err)
+			return
+		}
+	}
+	circle = options.Circle{
+		Center: gpx.Point{Latitude: values[0], Longitude: values[1]},
+		Radius: unit.Length(values[2]) * unit.Metre,
+	}
+	return
+}
+
+/*
+ReadCircles reads circles from the file identified with the provided fileName.
+Every non-empty line must follow the format LAT,LON,RADIUS (cf. ParseCircle).
+Lines starting with "#" are ignored.
+*/
+func ReadCircles(fileName string) (circles []options.Circle, err error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		err = errors.Join(errors.New(fmt.Sprintf("could not read file %v", fileName)), err)
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var circle options.Circle
+		circle, err = ParseCircle(line)
+		if err != nil {
+			return
+		}
+		circles = append(circles, circle)
+	}
+	return
+}
@@ -0,0 +1,59 @@
+package gpxio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+func TestParseGeoJSON(t *testing.T) {
+	area, err := ParseGeoJSON([]byte(`{
+  "type": "FeatureCollection",
+  "features": [{
+    "type": "Feature",
+    "properties": {},
+    "geometry": {
+      "type": "Polygon",
+      "coordinates": [
+        [[0.0, 0.0], [10.0, 0.0], [10.0, 10.0], [0.0, 10.0], [0.0, 0.0]],
+        [[4.0, 4.0], [6.0, 4.0], [6.0, 6.0], [4.0, 6.0], [4.0, 4.0]]
+      ]
+    }
+  }]
+}`))
+	assert.NoError(t, err)
+	assert.True(t, area.Contains(gpx.Point{Latitude: 2, Longitude: 8}))
+	assert.False(t, area.Contains(gpx.Point{Latitude: 5, Longitude: 5}))
+	assert.False(t, area.Contains(gpx.Point{Latitude: 11, Longitude: 5}))
+
+	_, err = ParseGeoJSON([]byte(`{"type": "Point", "coordinates": [1.0, 2.0]}`))
+	assert.Error(t, err)
+}
+
+func TestParseWKT(t *testing.T) {
+	area, err := ParseWKT("MULTIPOLYGON (((0 0, 10 0, 10 10, 0 10, 0 0)), ((20 20, 30 20, 30 30, 20 20)))")
+	assert.NoError(t, err)
+	assert.True(t, area.Contains(gpx.Point{Latitude: 5, Longitude: 5}))
+	assert.True(t, area.Contains(gpx.Point{Latitude: 22, Longitude: 28}))
+	assert.False(t, area.Contains(gpx.Point{Latitude: 15, Longitude: 15}))
+
+	area, err = ParseWKT("POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (4 4, 6 4, 6 6, 4 6, 4 4))")
+	assert.NoError(t, err)
+	assert.True(t, area.Contains(gpx.Point{Latitude: 2, Longitude: 8}))
+	assert.False(t, area.Contains(gpx.Point{Latitude: 5, Longitude: 5}))
+
+	_, err = ParseWKT("POLYGON ((0 0, 10 0, 10 10")
+	assert.Error(t, err)
+}
+
+func TestParseCircle(t *testing.T) {
+	circle, err := ParseCircle("50.1,8.2,150")
+	assert.NoError(t, err)
+	assert.Equal(t, 50.1, circle.Center.Latitude)
+	assert.Equal(t, 8.2, circle.Center.Longitude)
+	assert.Equal(t, 150.0, float64(circle.Radius))
+
+	_, err = ParseCircle("50.1,8.2")
+	assert.Error(t, err)
+}
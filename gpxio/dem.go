@@ -0,0 +1,534 @@
+package gpxio
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/abzicht/gogenericfunc/fun"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+DEM is a digital elevation model that is read from SRTM (".hgt") and GeoTIFF
+(".tif", ".tiff") tiles of a folder. Tiles must use geographic WGS84
+coordinates. Rasters are only read once they are needed.
+DEM implements options.ElevationModel.
+*/
+type DEM struct {
+	tiles []*demTile
+}
+
+/*
+demTile is a single raster. The sample at (row, col) lies at latitude
+north - row*latStep and longitude west + col*lonStep.
+*/
+type demTile struct {
+	fileName         string
+	rows, cols       int
+	north, west      float64
+	latStep, lonStep float64
+	noData           fun.Option[float64]
+	load             func(tile *demTile) ([]float64, error)
+	samples          []float64
+}
+
+var hgtName = regexp.MustCompile(`^([NSns])(\d{2})([EWew])(\d{3})\.hgt$`)
+
+/*
+ReadDEM prepares a DEM from all SRTM and GeoTIFF tiles (without recursion) in
+the provided folder.
+*/
+func ReadDEM(folderName string) (dem *DEM, err error) {
+	dirEntries, err := os.ReadDir(folderName)
+	if err != nil {
+		err = errors.Join(errors.New(fmt.Sprintf("could not read DEM folder %v", folderName)), err)
+		return
+	}
+	dem = &DEM{}
+	for _, entry := range dirEntries {
+		if entry.IsDir() {
+			continue
+		}
+		fileName := filepath.Join(folderName, entry.Name())
+		var tile *demTile
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".hgt":
+			tile, err = readHGTHeader(fileName)
+		case ".tif", ".tiff":
+			tile, err = readGeoTIFFHeader(fileName)
+		default:
+			continue
+		}
+		if err != nil {
+			err = errors.Join(errors.New(fmt.Sprintf("could not read DEM tile %v", fileName)), err)
+			return
+		}
+		dem.tiles = append(dem.tiles, tile)
+	}
+	if len(dem.tiles) == 0 {
+		err = errors.New(fmt.Sprintf("no DEM tiles (.hgt, .tif) found in %v", folderName))
+	}
+	return
+}
+
+/*
+Elevation returns the bilinearly interpolated elevation at the provided point.
+found is false, if no tile covers the point or if a surrounding sample is void.
+*/
+func (dem *DEM) Elevation(point gpx.Point) (elevation float64, found bool, err error) {
+	for _, tile := range dem.tiles {
+		row := (tile.north - point.Latitude) / tile.latStep
+		col := (point.Longitude - tile.west) / tile.lonStep
+		if row < 0 || col < 0 || row > float64(tile.rows-1) || col > float64(tile.cols-1) {
+			continue
+		}
+		if tile.samples == nil {
+			tile.samples, err = tile.load(tile)
+			if err != nil {
+				err = errors.Join(errors.New(fmt.Sprintf("could not read DEM tile %v", tile.fileName)), err)
+				return
+			}
+		}
+		return tile.bilinear(row, col)
+	}
+	return
+}
+
+func (tile *demTile) bilinear(row, col float64) (elevation float64, found bool, err error) {
+	r0 := min(int(math.Floor(row)), tile.rows-2)
+	c0 := min(int(math.Floor(col)), tile.cols-2)
+	fr, fc := row-float64(r0), col-float64(c0)
+	corners := [4]float64{}
+	for i, rc := range [4][2]int{{r0, c0}, {r0, c0 + 1}, {r0 + 1, c0}, {r0 + 1, c0 + 1}} {
+		sample := tile.samples[rc[0]*tile.cols+rc[1]]
+		if math.IsNaN(sample) || tile.noData.Equal(fun.NewSome(sample)) {
+			return
+		}
+		corners[i] = sample
+	}
+	top := corners[0]*(1-fc) + corners[1]*fc
+	bottom := corners[2]*(1-fc) + corners[3]*fc
+	return top*(1-fr) + bottom*fr, true, nil
+}
+
+/*
+readHGTHeader prepares an SRTM tile. Its position is derived from its file
+name (e.g., N50E008.hgt), its resolution from its file size.
+*/
+func readHGTHeader(fileName string) (tile *demTile, err error) {
+	match := hgtName.FindStringSubmatch(filepath.Base(fileName))
+	if match == nil {
+		err = errors.New("SRTM file names must follow the format N50E008.hgt")
+		return
+	}
+	lat, _ := strconv.Atoi(match[2])
+	lon, _ := strconv.Atoi(match[4])
+	if strings.EqualFold(match[1], "S") {
+		lat = -lat
+	}
+	if strings.EqualFold(match[3], "W") {
+		lon = -lon
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return
+	}
+	size := int(math.Round(math.Sqrt(float64(info.Size() / 2))))
+	if size < 2 || int64(size*size*2) != info.Size() {
+		err = errors.New(fmt.Sprintf("unexpected SRTM file size %v", info.Size()))
+		return
+	}
+	tile = &demTile{
+		fileName: fileName,
+		rows:     size,
+		cols:     size,
+		north:    float64(lat + 1),
+		west:     float64(lon),
+		latStep:  1 / float64(size-1),
+		lonStep:  1 / float64(size-1),
+		noData:   fun.NewSome[float64](-32768),
+		load: func(tile *demTile) (samples []float64, err error) {
+			data, err := os.ReadFile(tile.fileName)
+			if err != nil {
+				return
+			}
+			samples = make([]float64, tile.rows*tile.cols)
+			for i := range samples {
+				samples[i] = float64(int16(binary.BigEndian.Uint16(data[2*i:])))
+			}
+			return
+		},
+	}
+	return
+}
+
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffPredictor       = 317
+	tiffTileWidth       = 322
+	tiffTileLength      = 323
+	tiffTileOffsets     = 324
+	tiffTileByteCounts  = 325
+	tiffSampleFormat    = 339
+	tiffModelPixelScale = 33550
+	tiffModelTiepoint   = 33922
+	tiffGeoKeyDirectory = 34735
+	tiffGDALNoData      = 42113
+
+	geoKeyRasterType = 1025
+	rasterPixelPoint = 2
+)
+
+/*
+maxGeoTIFFSamples is the maximal number of samples of a GeoTIFF raster, which
+holds for tiles of up to 1 arc-second resolution that span several degrees.
+*/
+const maxGeoTIFFSamples = 1 << 28
+
+/*
+geoTIFF holds the parts of a GeoTIFF header that are required for reading its raster.
+*/
+type geoTIFF struct {
+	order                     binary.ByteOrder
+	width, height             int
+	bitsPerSample             int
+	sampleFormat              int
+	compression               int
+	predictor                 int
+	blockWidth, blockHeight   int
+	blockOffsets, blockCounts []uint64
+}
+
+/*
+readGeoTIFFHeader prepares a GeoTIFF tile. Only single-band rasters that are
+uncompressed or deflate-compressed are supported.
+*/
+func readGeoTIFFHeader(fileName string) (tile *demTile, err error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
+	tags, order, err := readTIFFTags(file, info.Size())
+	if err != nil {
+		return
+	}
+	single := func(tag uint16, fallback uint64) uint64 {
+		if values, found := tags[tag]; found && len(values.ints) > 0 {
+			return values.ints[0]
+		}
+		return fallback
+	}
+	header := geoTIFF{
+		order:         order,
+		width:         int(single(tiffImageWidth, 0)),
+		height:        int(single(tiffImageLength, 0)),
+		bitsPerSample: int(single(tiffBitsPerSample, 1)),
+		sampleFormat:  int(single(tiffSampleFormat, 1)),
+		compression:   int(single(tiffCompression, 1)),
+		predictor:     int(single(tiffPredictor, 1)),
+	}
+	if single(tiffSamplesPerPixel, 1) != 1 {
+		err = errors.New("only single-band GeoTIFF files are supported")
+		return
+	}
+	if header.compression != 1 && header.compression != 8 && header.compression != 32946 {
+		err = errors.New(fmt.Sprintf("unsupported GeoTIFF compression %v; only uncompressed and deflate are supported", header.compression))
+		return
+	}
+	if header.predictor != 1 && header.predictor != 2 {
+		err = errors.New(fmt.Sprintf("unsupported GeoTIFF predictor %v", header.predictor))
+		return
+	}
+	if _, tiled := tags[tiffTileOffsets]; tiled {
+		header.blockWidth = int(single(tiffTileWidth, 0))
+		header.blockHeight = int(single(tiffTileLength, 0))
+		header.blockOffsets = tags[tiffTileOffsets].ints
+		header.blockCounts = tags[tiffTileByteCounts].ints
+	} else {
+		header.blockWidth = header.width
+		header.blockHeight = int(single(tiffRowsPerStrip, uint64(header.height)))
+		header.blockOffsets = tags[tiffStripOffsets].ints
+		header.blockCounts = tags[tiffStripByteCounts].ints
+	}
+	if header.width < 2 || header.height < 2 || header.blockWidth == 0 || header.blockHeight == 0 || len(header.blockOffsets) != len(header.blockCounts) {
+		err = errors.New("incomplete GeoTIFF raster description")
+		return
+	}
+	if uint64(header.width)*uint64(header.height) > maxGeoTIFFSamples || uint64(header.blockWidth)*uint64(header.blockHeight) > maxGeoTIFFSamples {
+		err = errors.New(fmt.Sprintf("GeoTIFF raster of %vx%v samples in blocks of %vx%v is too large", header.width, header.height, header.blockWidth, header.blockHeight))
+		return
+	}
+	blocksAcross := (header.width + header.blockWidth - 1) / header.blockWidth
+	blocksDown := (header.height + header.blockHeight - 1) / header.blockHeight
+	if len(header.blockOffsets) < blocksAcross*blocksDown {
+		err = errors.New(fmt.Sprintf("GeoTIFF raster requires %v strips or tiles; got %v", blocksAcross*blocksDown, len(header.blockOffsets)))
+		return
+	}
+	for i := range header.blockOffsets {
+		if header.blockOffsets[i]+header.blockCounts[i] > uint64(info.Size()) {
+			err = errors.New(fmt.Sprintf("GeoTIFF strip or tile %v exceeds the file size of %v bytes", i, info.Size()))
+			return
+		}
+	}
+	scale, tiepoint := tags[tiffModelPixelScale].floats, tags[tiffModelTiepoint].floats
+	if len(scale) < 2 || len(tiepoint) < 6 {
+		err = errors.New("GeoTIFF files require ModelPixelScale and ModelTiepoint")
+		return
+	}
+	// samples represent areas by default, so their values belong to their centers
+	offset := 0.5
+	if keys := tags[tiffGeoKeyDirectory].ints; len(keys) >= 4 {
+		for i := 4; i+3 < len(keys); i += 4 {
+			if keys[i] == geoKeyRasterType && keys[i+1] == 0 && keys[i+3] == rasterPixelPoint {
+				offset = 0
+			}
+		}
+	}
+	tile = &demTile{
+		fileName: fileName,
+		rows:     header.height,
+		cols:     header.width,
+		noData:   fun.NewNone[float64](),
+		west:     tiepoint[3] + (offset-tiepoint[0])*scale[0],
+		north:    tiepoint[4] - (offset-tiepoint[1])*scale[1],
+		lonStep:  scale[0],
+		latStep:  scale[1],
+		load: func(tile *demTile) ([]float64, error) {
+			return header.readRaster(tile.fileName)
+		},
+	}
+	if noData, found := tags[tiffGDALNoData]; found {
+		value, err2 := strconv.ParseFloat(strings.Trim(noData.ascii, "\x00 "), 64)
+		if err2 == nil {
+			tile.noData = fun.NewSome(value)
+		}
+	}
+	return
+}
+
+/*
+tiffValues holds the values of a TIFF tag.
+*/
+type tiffValues struct {
+	ints   []uint64
+	floats []float64
+	ascii  string
+}
+
+/*
+readTIFFTags reads all tags of the first image file directory of a TIFF file
+of the provided size. Offsets and counts are checked against the file size, so
+that malformed files cause errors instead of huge allocations.
+*/
+func readTIFFTags(reader io.ReaderAt, fileSize int64) (tags map[uint16]tiffValues, order binary.ByteOrder, err error) {
+	header := make([]byte, 8)
+	_, err = reader.ReadAt(header, 0)
+	if err != nil {
+		return
+	}
+	switch string(header[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		err = errors.New("not a TIFF file")
+		return
+	}
+	if order.Uint16(header[2:]) != 42 {
+		err = errors.New("only classic TIFF files are supported")
+		return
+	}
+	ifdOffset := int64(order.Uint32(header[4:]))
+	if ifdOffset+2 > fileSize {
+		err = errors.New(fmt.Sprintf("TIFF image file directory at %v exceeds the file size of %v bytes", ifdOffset, fileSize))
+		return
+	}
+	countBytes := make([]byte, 2)
+	_, err = reader.ReadAt(countBytes, ifdOffset)
+	if err != nil {
+		return
+	}
+	entryCount := int64(order.Uint16(countBytes))
+	if ifdOffset+2+12*entryCount > fileSize {
+		err = errors.New(fmt.Sprintf("TIFF image file directory of %v entries exceeds the file size of %v bytes", entryCount, fileSize))
+		return
+	}
+	entries := make([]byte, 12*entryCount)
+	_, err = reader.ReadAt(entries, ifdOffset+2)
+	if err != nil {
+		return
+	}
+	typeSizes := map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 8: 2, 9: 4, 11: 4, 12: 8, 16: 8}
+	tags = map[uint16]tiffValues{}
+	for i := 0; i < len(entries); i += 12 {
+		tag := order.Uint16(entries[i:])
+		dataType := order.Uint16(entries[i+2:])
+		count := int(order.Uint32(entries[i+4:]))
+		size, known := typeSizes[dataType]
+		if !known {
+			continue
+		}
+		data := entries[i+8 : i+12]
+		if dataSize := int64(size) * int64(count); dataSize > 4 {
+			dataOffset := int64(order.Uint32(entries[i+8:]))
+			if dataOffset+dataSize > fileSize {
+				err = errors.New(fmt.Sprintf("TIFF tag %v with %v values exceeds the file size of %v bytes", tag, count, fileSize))
+				return
+			}
+			data = make([]byte, dataSize)
+			_, err = reader.ReadAt(data, dataOffset)
+			if err != nil {
+				return
+			}
+		}
+		values := tiffValues{}
+		for j := 0; j < count; j++ {
+			switch dataType {
+			case 1:
+				values.ints = append(values.ints, uint64(data[j]))
+			case 3, 8:
+				values.ints = append(values.ints, uint64(order.Uint16(data[2*j:])))
+			case 4, 9:
+				values.ints = append(values.ints, uint64(order.Uint32(data[4*j:])))
+			case 16:
+				values.ints = append(values.ints, order.Uint64(data[8*j:]))
+			case 11:
+				values.floats = append(values.floats, float64(math.Float32frombits(order.Uint32(data[4*j:]))))
+			case 12:
+				values.floats = append(values.floats, math.Float64frombits(order.Uint64(data[8*j:])))
+			}
+		}
+		if dataType == 2 {
+			values.ascii = string(data[:count])
+		}
+		tags[tag] = values
+	}
+	return
+}
+
+/*
+readRaster decodes all samples of the GeoTIFF file, row by row.
+*/
+func (g geoTIFF) readRaster(fileName string) (samples []float64, err error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	bytesPerSample := g.bitsPerSample / 8
+	if bytesPerSample*8 != g.bitsPerSample {
+		err = errors.New(fmt.Sprintf("unsupported GeoTIFF sample size of %v bits", g.bitsPerSample))
+		return
+	}
+	samples = make([]float64, g.width*g.height)
+	blocksAcross := (g.width + g.blockWidth - 1) / g.blockWidth
+	for blockIndex := range g.blockOffsets {
+		data := make([]byte, g.blockCounts[blockIndex])
+		_, err = file.ReadAt(data, int64(g.blockOffsets[blockIndex]))
+		if err != nil {
+			return
+		}
+		if g.compression != 1 {
+			var zr io.ReadCloser
+			zr, err = zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return
+			}
+			data, err = io.ReadAll(io.LimitReader(zr, int64(g.blockWidth*g.blockHeight*bytesPerSample)))
+			zr.Close()
+			if err != nil {
+				return
+			}
+		}
+		if len(data) < g.blockWidth*g.blockHeight*bytesPerSample {
+			// the last strip may be shorter
+			data = append(data, make([]byte, g.blockWidth*g.blockHeight*bytesPerSample-len(data))...)
+		}
+		top := (blockIndex / blocksAcross) * g.blockHeight
+		left := (blockIndex % blocksAcross) * g.blockWidth
+		for r := 0; r < g.blockHeight && top+r < g.height; r++ {
+			var previous float64
+			for c := 0; c < g.blockWidth; c++ {
+				var value float64
+				value, err = g.sample(data[(r*g.blockWidth+c)*bytesPerSample:])
+				if err != nil {
+					return
+				}
+				if g.predictor == 2 && c > 0 {
+					value = g.wrap(value + previous)
+				}
+				previous = value
+				if left+c < g.width {
+					samples[(top+r)*g.width+left+c] = value
+				}
+			}
+		}
+	}
+	return
+}
+
+/*
+sample decodes a single sample at the start of data.
+*/
+func (g geoTIFF) sample(data []byte) (float64, error) {
+	switch {
+	case g.sampleFormat == 3 && g.bitsPerSample == 32:
+		return float64(math.Float32frombits(g.order.Uint32(data))), nil
+	case g.sampleFormat == 3 && g.bitsPerSample == 64:
+		return math.Float64frombits(g.order.Uint64(data)), nil
+	case g.sampleFormat == 2 && g.bitsPerSample == 16:
+		return float64(int16(g.order.Uint16(data))), nil
+	case g.sampleFormat == 2 && g.bitsPerSample == 32:
+		return float64(int32(g.order.Uint32(data))), nil
+	case g.sampleFormat == 1 && g.bitsPerSample == 8:
+		return float64(data[0]), nil
+	case g.sampleFormat == 1 && g.bitsPerSample == 16:
+		return float64(g.order.Uint16(data)), nil
+	case g.sampleFormat == 1 && g.bitsPerSample == 32:
+		return float64(g.order.Uint32(data)), nil
+	}
+	return 0, errors.New(fmt.Sprintf("unsupported GeoTIFF sample format %v with %v bits", g.sampleFormat, g.bitsPerSample))
+}
+
+/*
+wrap applies the integer overflow of the sample type, which the horizontal
+differencing predictor relies on.
+*/
+func (g geoTIFF) wrap(value float64) float64 {
+	switch {
+	case g.sampleFormat == 2 && g.bitsPerSample == 16:
+		return float64(int16(int64(value)))
+	case g.sampleFormat == 2 && g.bitsPerSample == 32:
+		return float64(int32(int64(value)))
+	case g.sampleFormat == 1 && g.bitsPerSample == 8:
+		return float64(uint8(int64(value)))
+	case g.sampleFormat == 1 && g.bitsPerSample == 16:
+		return float64(uint16(int64(value)))
+	case g.sampleFormat == 1 && g.bitsPerSample == 32:
+		return float64(uint32(int64(value)))
+	}
+	return value
+}
@@ -0,0 +1,188 @@
+package gpxio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+func TestReadDEMHGT(t *testing.T) {
+	folder := t.TempDir()
+	// 3x3 samples, rows from north to south
+	samples := []int16{100, 200, 300, 400, 500, 600, 700, 800, -32768}
+	data := bytes.NewBuffer([]byte{})
+	assert.NoError(t, binary.Write(data, binary.BigEndian, samples))
+	assert.NoError(t, os.WriteFile(filepath.Join(folder, "N50E008.hgt"), data.Bytes(), 0644))
+
+	dem, err := ReadDEM(folder)
+	assert.NoError(t, err)
+	elevation, found, err := dem.Elevation(gpx.Point{Latitude: 51, Longitude: 8})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 100.0, elevation)
+	elevation, found, err = dem.Elevation(gpx.Point{Latitude: 50.75, Longitude: 8.25})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.InDelta(t, 300.0, elevation, 1e-9)
+	// void sample
+	_, found, err = dem.Elevation(gpx.Point{Latitude: 50.25, Longitude: 8.75})
+	assert.NoError(t, err)
+	assert.False(t, found)
+	// not covered
+	_, found, err = dem.Elevation(gpx.Point{Latitude: 52, Longitude: 8})
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+/*
+writeGeoTIFF writes an uncompressed, little endian float32 GeoTIFF whose
+samples represent areas of 1x1 degrees, starting at the provided north-west
+corner.
+*/
+func writeGeoTIFF(t *testing.T, fileName string, width, height int, north, west float64, samples []float32) {
+	type entry struct {
+		tag, dataType uint16
+		values        []byte
+	}
+	u16 := func(values ...uint16) []byte {
+		b := bytes.NewBuffer([]byte{})
+		binary.Write(b, binary.LittleEndian, values)
+		return b.Bytes()
+	}
+	u32 := func(values ...uint32) []byte {
+		b := bytes.NewBuffer([]byte{})
+		binary.Write(b, binary.LittleEndian, values)
+		return b.Bytes()
+	}
+	f64 := func(values ...float64) []byte {
+		b := bytes.NewBuffer([]byte{})
+		binary.Write(b, binary.LittleEndian, values)
+		return b.Bytes()
+	}
+	raster := bytes.NewBuffer([]byte{})
+	binary.Write(raster, binary.LittleEndian, samples)
+	entries := []entry{
+		{256, 3, u16(uint16(width))},
+		{257, 3, u16(uint16(height))},
+		{258, 3, u16(32)},
+		{259, 3, u16(1)},
+		{273, 4, nil},
+		{277, 3, u16(1)},
+		{278, 3, u16(uint16(height))},
+		{279, 4, u32(uint32(raster.Len()))},
+		{339, 3, u16(3)},
+		{33550, 12, f64(1, 1, 0)},
+		{33922, 12, f64(0, 0, 0, west, north, 0)},
+	}
+	sizes := map[uint16]int{3: 2, 4: 4, 12: 8}
+	ifdOffset := 8
+	dataOffset := ifdOffset + 2 + 12*len(entries) + 4
+	extra := bytes.NewBuffer([]byte{})
+	rasterOffset := dataOffset
+	for _, e := range entries {
+		if len(e.values) > 4 {
+			rasterOffset += len(e.values)
+		}
+	}
+	entries[4].values = u32(uint32(rasterOffset))
+	ifd := bytes.NewBuffer(u16(uint16(len(entries))))
+	for _, e := range entries {
+		ifd.Write(u16(e.tag, e.dataType))
+		ifd.Write(u32(uint32(len(e.values) / sizes[e.dataType])))
+		if len(e.values) > 4 {
+			ifd.Write(u32(uint32(dataOffset + extra.Len())))
+			extra.Write(e.values)
+		} else {
+			ifd.Write(append(e.values, make([]byte, 4-len(e.values))...))
+		}
+	}
+	ifd.Write(u32(0))
+	file := bytes.NewBuffer([]byte("II"))
+	file.Write(u16(42))
+	file.Write(u32(uint32(ifdOffset)))
+	file.Write(ifd.Bytes())
+	file.Write(extra.Bytes())
+	file.Write(raster.Bytes())
+	assert.NoError(t, os.WriteFile(fileName, file.Bytes(), 0644))
+}
+
+func TestReadDEMGeoTIFF(t *testing.T) {
+	folder := t.TempDir()
+	writeGeoTIFF(t, filepath.Join(folder, "dem.tif"), 3, 2, 52, 8, []float32{100, 200, float32(math.NaN()), 300, 400, 500})
+
+	dem, err := ReadDEM(folder)
+	assert.NoError(t, err)
+	// sample centers lie at 51.5 and 50.5 / 8.5, 9.5, and 10.5
+	elevation, found, err := dem.Elevation(gpx.Point{Latitude: 51.5, Longitude: 8.5})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 100.0, elevation)
+	elevation, found, err = dem.Elevation(gpx.Point{Latitude: 51.5, Longitude: 9.0})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.InDelta(t, 150.0, elevation, 1e-9)
+	elevation, found, err = dem.Elevation(gpx.Point{Latitude: 51.0, Longitude: 9.0})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.InDelta(t, 250.0, elevation, 1e-9)
+	// void sample
+	_, found, err = dem.Elevation(gpx.Point{Latitude: 51.0, Longitude: 10.0})
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestReadDEMMalformedGeoTIFF(t *testing.T) {
+	folder := t.TempDir()
+	writeGeoTIFF(t, filepath.Join(folder, "dem.tif"), 3, 2, 52, 8, []float32{100, 200, 300, 400, 500, 600})
+	valid, err := os.ReadFile(filepath.Join(folder, "dem.tif"))
+	assert.NoError(t, err)
+	// entries of the image file directory start at byte 10 and hold 12 bytes each
+	entry := func(index int) int {
+		return 10 + 12*index
+	}
+	corruptions := map[string]func(data []byte) []byte{
+		"directory offset": func(data []byte) []byte {
+			binary.LittleEndian.PutUint32(data[4:], 0xFFFFFF00)
+			return data
+		},
+		"entry count": func(data []byte) []byte {
+			binary.LittleEndian.PutUint16(data[8:], 0xFFFF)
+			return data
+		},
+		"tag count": func(data []byte) []byte {
+			// ModelTiepoint
+			binary.LittleEndian.PutUint32(data[entry(10)+4:], 0xFFFFFFFF)
+			return data
+		},
+		"tag offset": func(data []byte) []byte {
+			binary.LittleEndian.PutUint32(data[entry(10)+8:], 0xFFFFFF00)
+			return data
+		},
+		"strip offset": func(data []byte) []byte {
+			binary.LittleEndian.PutUint32(data[entry(4)+8:], 0xFFFFFF00)
+			return data
+		},
+		"truncated raster": func(data []byte) []byte {
+			return data[:len(data)-4]
+		},
+		"raster size": func(data []byte) []byte {
+			// ImageWidth and RowsPerStrip
+			binary.LittleEndian.PutUint16(data[entry(0)+8:], 0xFFFF)
+			binary.LittleEndian.PutUint16(data[entry(6)+8:], 0xFFFF)
+			return data
+		},
+	}
+	for name, corrupt := range corruptions {
+		folder := t.TempDir()
+		data := corrupt(bytes.Clone(valid))
+		assert.NoError(t, os.WriteFile(filepath.Join(folder, "dem.tif"), data, 0644))
+		_, err := ReadDEM(folder)
+		assert.Error(t, err, name)
+	}
+}
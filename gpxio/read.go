@@ -47,7 +47,12 @@ func ReadFile(fileName string) (gpxFile gpx.GPX, err error) {
 		err = errors.Join(errors.New(fmt.Sprintf("could not open file %v", fileName)), err)
 		return
 	}
+	defer reader.Close()
 	gpxFilePointer, err := gpx.Parse(reader)
+	if err != nil {
+		err = errors.Join(errors.New(fmt.Sprintf("could not parse file %v", fileName)), err)
+		return
+	}
 	gpxFile = *gpxFilePointer
 	return
 }
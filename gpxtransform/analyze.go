@@ -4,7 +4,9 @@ import (
 	"fmt"
 
 	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
 	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
 )
 
 func AnalyzeFile() config.GPXFileTransform {
@@ -33,3 +35,110 @@ func CountFile() config.GPXFileTransform {
 		return []gpx.GPX{}, nil
 	}
 }
+
+/*
+AnalyzeElevation prints the ascent and descent of every track and of the whole
+file, ignoring elevation changes smaller than threshold (cf. UphillDownhill).
+*/
+func AnalyzeElevation(threshold unit.Length) config.GPXFileTransform {
+	return func(gpxFile gpx.GPX) (files []gpx.GPX, err error) {
+		total := gpx.UphillDownhill{}
+		for trackIndex, track := range gpxFile.Tracks {
+			trackTotal := gpx.UphillDownhill{}
+			for _, segment := range track.Segments {
+				ud := UphillDownhill(segment, threshold)
+				trackTotal.Uphill += ud.Uphill
+				trackTotal.Downhill += ud.Downhill
+			}
+			fmt.Printf("Track %v (%v): Ascent: %.1fm; Descent: %.1fm\n", trackIndex+1, track.Name, trackTotal.Uphill, trackTotal.Downhill)
+			total.Uphill += trackTotal.Uphill
+			total.Downhill += trackTotal.Downhill
+		}
+		fmt.Printf("Total: Ascent: %.1fm; Descent: %.1fm\n", total.Uphill, total.Downhill)
+		return []gpx.GPX{}, nil
+	}
+}
+
+/*
+AnalyzeClimbs prints every climb of every track that gains at least prominence
+(cf. options.FindClimbs), including its length, gain, average and maximum
+grade, and category.
+*/
+func AnalyzeClimbs(prominence unit.Length) config.GPXFileTransform {
+	return func(gpxFile gpx.GPX) (files []gpx.GPX, err error) {
+		for trackIndex, track := range gpxFile.Tracks {
+			climbs := []options.Climb{}
+			for _, segment := range track.Segments {
+				climbs = append(climbs, options.FindClimbs(segment, prominence)...)
+			}
+			if len(climbs) == 0 {
+				fmt.Printf("Track %v (%v): No climbs\n", trackIndex+1, track.Name)
+			}
+			for climbIndex, climb := range climbs {
+				fmt.Printf("Track %v (%v), climb %v: Length: %.2fkm; Gain: %.0fm; Average grade: %.1f%%; Maximum grade: %.1f%%; Category: %v\n", trackIndex+1, track.Name, climbIndex+1, float64(climb.Length)/1000, float64(climb.Gain), climb.AverageGrade, climb.MaxGrade, climb.Category())
+			}
+		}
+		return []gpx.GPX{}, nil
+	}
+}
+
+/*
+UphillDownhill computes the ascent and descent of a segment with hysteresis:
+a climb (descent) is only counted once the elevation has risen (fallen) by at
+least threshold from the last counted valley (summit), or from the lowest
+(highest) point before the first climb (descent). This way, noise below
+threshold does not add up. Points without elevation are ignored.
+*/
+func UphillDownhill(segment gpx.GPXTrackSegment, threshold unit.Length) (ud gpx.UphillDownhill) {
+	const (
+		flat = iota
+		up
+		down
+	)
+	trend := flat
+	var reference, extreme float64
+	// low and high are the lowest and highest elevation before the first
+	// climb or descent, either of which becomes its reference
+	var low, high float64
+	started := false
+	for _, point := range segment.Points {
+		if point.Elevation.Null() {
+			continue
+		}
+		elevation := point.Elevation.Value()
+		if !started {
+			low, high, started = elevation, elevation, true
+			continue
+		}
+		switch trend {
+		case flat:
+			low, high = min(low, elevation), max(high, elevation)
+			if elevation-low >= float64(threshold) {
+				trend, reference, extreme = up, low, elevation
+			} else if high-elevation >= float64(threshold) {
+				trend, reference, extreme = down, high, elevation
+			}
+		case up:
+			if elevation > extreme {
+				extreme = elevation
+			} else if extreme-elevation >= float64(threshold) {
+				ud.Uphill += extreme - reference
+				trend, reference, extreme = down, extreme, elevation
+			}
+		case down:
+			if elevation < extreme {
+				extreme = elevation
+			} else if elevation-extreme >= float64(threshold) {
+				ud.Downhill += reference - extreme
+				trend, reference, extreme = up, extreme, elevation
+			}
+		}
+	}
+	switch trend {
+	case up:
+		ud.Uphill += extreme - reference
+	case down:
+		ud.Downhill += reference - extreme
+	}
+	return
+}
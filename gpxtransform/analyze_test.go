@@ -0,0 +1,70 @@
+package gpxtransform
+
+import (
+	"testing"
+
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/unit"
+)
+
+func TestUphillDownhill(t *testing.T) {
+	segment := elevationSegment(100, 102, 101, 103, 102, 120, 119, 121, 100, 101, 99)
+	ud := UphillDownhill(segment, 5*unit.Metre)
+	assert.Equal(t, 21.0, ud.Uphill)
+	assert.Equal(t, 22.0, ud.Downhill)
+
+	ud = UphillDownhill(segment, 0*unit.Metre)
+	assert.Equal(t, 25.0, ud.Uphill)
+	assert.Equal(t, 26.0, ud.Downhill)
+
+	// a slow drift below the threshold is followed by a climb from its lowest point
+	ud = UphillDownhill(elevationSegment(100, 97, 94, 91, 100, 111), 10*unit.Metre)
+	assert.Equal(t, 20.0, ud.Uphill)
+	assert.Equal(t, 0.0, ud.Downhill)
+	ud = UphillDownhill(elevationSegment(100, 103, 106, 109, 100, 89), 10*unit.Metre)
+	assert.Equal(t, 0.0, ud.Uphill)
+	assert.Equal(t, 20.0, ud.Downhill)
+}
+
+func TestFindClimbs(t *testing.T) {
+	segment := elevationSegment(100, 105, 140, 138, 145, 110, 100, 102, 98, 130, 125)
+	extrema := options.FindExtrema(segment, 30*unit.Metre)
+	assert.Equal(t, []options.Extremum{{Index: 4, Summit: true}, {Index: 8, Summit: false}}, extrema)
+
+	climbs := options.FindClimbs(segment, 30*unit.Metre)
+	assert.Equal(t, 2, len(climbs))
+	assert.Equal(t, 0, climbs[0].Start)
+	assert.Equal(t, 4, climbs[0].End)
+	assert.Equal(t, unit.Length(45), climbs[0].Gain)
+	// the climb at the end of the segment ends at its highest point
+	assert.Equal(t, 8, climbs[1].Start)
+	assert.Equal(t, 9, climbs[1].End)
+	assert.Equal(t, unit.Length(32), climbs[1].Gain)
+
+	assert.Equal(t, 0, len(options.FindClimbs(segment, 50*unit.Metre)))
+
+	// 19 legs at 1m per leg, followed by 10 legs at 3m per leg (about 9% and 27%)
+	elevations := []float64{}
+	for i := 0; i < 20; i++ {
+		elevations = append(elevations, 100+float64(i))
+	}
+	for i := 1; i <= 10; i++ {
+		elevations = append(elevations, 119+3*float64(i))
+	}
+	climbs = options.FindClimbs(elevationSegment(elevations...), 30*unit.Metre)
+	assert.Equal(t, 1, len(climbs))
+	assert.InDelta(t, 322, float64(climbs[0].Length), 1)
+	assert.InDelta(t, 15.2, climbs[0].AverageGrade, 0.1)
+	assert.InDelta(t, 27, climbs[0].MaxGrade, 0.1)
+}
+
+func TestClimbCategory(t *testing.T) {
+	assert.Equal(t, "HC", options.Climb{Length: 15000, AverageGrade: 7}.Category())
+	assert.Equal(t, "1", options.Climb{Length: 10000, AverageGrade: 7}.Category())
+	assert.Equal(t, "2", options.Climb{Length: 5000, AverageGrade: 7}.Category())
+	assert.Equal(t, "3", options.Climb{Length: 3000, AverageGrade: 6}.Category())
+	assert.Equal(t, "4", options.Climb{Length: 2000, AverageGrade: 5}.Category())
+	assert.Equal(t, "-", options.Climb{Length: 1000, AverageGrade: 5}.Category())
+	assert.Equal(t, "-", options.Climb{Length: 50000, AverageGrade: 2}.Category())
+}
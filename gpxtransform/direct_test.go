@@ -2,6 +2,7 @@ package gpxtransform
 
 import (
 	"testing"
+	"time"
 
 	"github.com/abzicht/gpsplit/gpxtransform/config"
 	"github.com/abzicht/gpsplit/gpxtransform/options"
@@ -27,3 +28,223 @@ func TestDirect(t *testing.T) {
 	assert.Equal(t, 2, len(gpxFiles[0].Tracks))
 	assert.Equal(t, 0, len(gpxFiles[0].Tracks[0].Segments))
 }
+
+func TestDirectKalman(t *testing.T) {
+	segment := jitteredSegment()
+	for _, rts := range []bool{false, true} {
+		segments, err := Direct(options.Kalman(0.5, 5*unit.Metre, rts))(segment)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(segments))
+		assert.Equal(t, len(segment.Points), len(segments[0].Points))
+		assert.Less(t, segments[0].Length2D(), segment.Length2D()/2)
+		assert.Equal(t, segment.Points[10].Timestamp, segments[0].Points[10].Timestamp)
+	}
+}
+
+func TestDirectElevationSmoothing(t *testing.T) {
+	segment := elevationSegment(100, 104, 100, 104, 100, 104, 100)
+	segments, err := Direct(options.MovingAverageElevation(3))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, segments[0].Points[0].Elevation.Value())
+	assert.InDelta(t, 304.0/3, segments[0].Points[1].Elevation.Value(), 1e-9)
+	assert.Equal(t, 104.0, segment.Points[1].Elevation.Value())
+
+	// a quadratic polynomial is not modified by Savitzky-Golay smoothing
+	segment = elevationSegment(1, 4, 9, 16, 25, 36, 49)
+	segments, err = Direct(options.SavitzkyGolayElevation(5))(segment)
+	assert.NoError(t, err)
+	for i, point := range segments[0].Points {
+		assert.InDelta(t, float64((i+1)*(i+1)), point.Elevation.Value(), 1e-9)
+	}
+
+	_, err = Direct(options.SavitzkyGolayElevation(4))(segment)
+	assert.Error(t, err)
+}
+
+func TestDirectTimeResample(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	segment := gpxFile.Tracks[0].Segments[0]
+	segments, err := Direct(options.TimeResample(20*time.Second, 0))(segment)
+	assert.NoError(t, err)
+	// 07:58:00 to 08:03:00
+	assert.Equal(t, 16, len(segments[0].Points))
+	assert.InDelta(t, 50.001+0.001/3, segments[0].Points[4].Latitude, 1e-9)
+	assert.InDelta(t, 101+1.0/3, segments[0].Points[4].Elevation.Value(), 1e-9)
+	assert.Equal(t, time.Date(2024, 5, 6, 7, 59, 20, 0, time.UTC), segments[0].Points[4].Timestamp)
+
+	// remove the points at 07:59 and 08:00
+	segment.Points = append(segment.Points[0:1], segment.Points[3:]...)
+	segments, err = Direct(options.TimeResample(20*time.Second, 90*time.Second))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 1+7, len(segments[0].Points))
+
+	// segments between two multiples of step are removed instead of left empty
+	segment = gpx.GPXTrackSegment{Points: []gpx.GPXPoint{fixturePoint(50, 5*time.Second), fixturePoint(50.001, 15*time.Second)}}
+	segments, err = Direct(options.TimeResample(20*time.Second, 0))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(segments))
+
+	// segments without timestamps are not modified
+	segment = gpx.GPXTrackSegment{Points: []gpx.GPXPoint{{Point: gpx.Point{Latitude: 50, Longitude: 8}}, {Point: gpx.Point{Latitude: 50.001, Longitude: 8}}}}
+	segments, err = Direct(options.TimeResample(20*time.Second, 0))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, []gpx.GPXTrackSegment{segment}, segments)
+}
+
+func TestDirectDistanceResample(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	segment := gpxFile.Tracks[0].Segments[0]
+	segments, err := Direct(options.DistanceResample(50 * unit.Metre))(segment)
+	assert.NoError(t, err)
+	// the segment is about 556 meters long
+	assert.Equal(t, 13, len(segments[0].Points))
+	for i := 1; i < len(segments[0].Points)-1; i++ {
+		assert.InDelta(t, 50, segments[0].Points[i].Distance2D(&segments[0].Points[i-1]), 1e-6)
+	}
+	assert.Equal(t, segment.Points[5].Timestamp, segments[0].Points[12].Timestamp)
+	assert.True(t, segments[0].Points[1].Timestamp.After(segment.Points[0].Timestamp))
+	assert.True(t, segments[0].Points[1].Timestamp.Before(segment.Points[1].Timestamp))
+}
+
+func TestDirectDensify(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	// consecutive points are about 111 meters apart
+	tc := config.NewTransformConfig(config.WithSegmentTransform(Direct(options.Densify(50 * unit.Metre))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	points := gpxFiles[0].Tracks[0].Segments[0].Points
+	assert.Equal(t, 6+5*2, len(points))
+	assert.False(t, options.IsSynthetic(points[0]))
+	assert.True(t, options.IsSynthetic(points[1]))
+	assert.InDelta(t, 50.0000+0.001/3, points[1].Latitude, 1e-7)
+	assert.Equal(t, time.Date(2024, 5, 6, 7, 58, 20, 0, time.UTC), points[1].Timestamp)
+
+	// synthetic points are recognized after writing and parsing the file
+	xmlBytes, err := gpxFiles[0].ToXml(gpx.ToXmlParams{Version: "1.1"})
+	assert.NoError(t, err)
+	gpxFile, err = gpx.ParseBytes(xmlBytes)
+	assert.NoError(t, err)
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Filter(options.RemoveSynthetic())))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+
+	// short gaps are interpolated linearly, long gaps along the great circle,
+	// which runs north of the parallel between two points in the north
+	segment := gpx.GPXTrackSegment{Points: []gpx.GPXPoint{
+		{Point: gpx.Point{Latitude: 50, Longitude: 8}},
+		{Point: gpx.Point{Latitude: 50, Longitude: 8.01}},
+	}}
+	segments, err := Direct(options.Densify(500 * unit.Metre))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(segments[0].Points))
+	assert.Equal(t, 50.0, segments[0].Points[1].Latitude)
+	assert.InDelta(t, 8.005, segments[0].Points[1].Longitude, 1e-9)
+
+	segment.Points[1].Longitude = 40
+	segments, err = Direct(options.Densify(2000 * unit.Kilo * unit.Metre))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(segments[0].Points))
+	assert.Greater(t, segments[0].Points[1].Latitude, 51.0)
+	assert.InDelta(t, 24, segments[0].Points[1].Longitude, 1e-9)
+}
+
+func TestDirectVisvalingamWhyatt(t *testing.T) {
+	segment := jitteredSegment()
+	segments, err := Direct(options.SimplifyToPoints(10))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(segments[0].Points))
+	assert.Equal(t, segment.Points[0], segments[0].Points[0])
+	assert.Equal(t, segment.Points[59], segments[0].Points[9])
+
+	segments, err = Direct(options.SimplifyToPoints(100))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 60, len(segments[0].Points))
+
+	// the jitter forms triangles of about 0.5 * 3m * 5.7m
+	segments, err = Direct(options.VisvalingamWhyatt(1))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 60, len(segments[0].Points))
+	segments, err = Direct(options.VisvalingamWhyatt(10))(segment)
+	assert.NoError(t, err)
+	assert.Less(t, len(segments[0].Points), 60)
+}
+
+func TestDirectSynchronizedDouglasPeucker(t *testing.T) {
+	// heading north with constant speed, pausing between minute 5 and 10
+	segment := gpx.GPXTrackSegment{}
+	latitude := 50.0
+	for i := 0; i <= 15; i++ {
+		if i <= 5 || i > 10 {
+			latitude += 0.001
+		}
+		segment.Points = append(segment.Points, fixturePoint(latitude, time.Duration(i)*time.Minute))
+	}
+	segments, err := Direct(options.DouglasPeucker(5 * unit.Metre))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(segments[0].Points))
+
+	segments, err = Direct(options.SynchronizedDouglasPeucker(5 * unit.Metre))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(segments[0].Points))
+	assert.Equal(t, segment.Points[5], segments[0].Points[1])
+	assert.Equal(t, segment.Points[10], segments[0].Points[2])
+}
+
+func TestDirectEqualParts(t *testing.T) {
+	segment := gpx.GPXTrackSegment{}
+	// 11 points, 10 legs of equal length; the first 5 legs take 1 minute each, the last 5 legs 3 minutes each
+	offset := time.Duration(0)
+	for i := 0; i <= 10; i++ {
+		segment.Points = append(segment.Points, fixturePoint(50+0.001*float64(i), offset))
+		if i < 5 {
+			offset += time.Minute
+		} else {
+			offset += 3 * time.Minute
+		}
+	}
+	segments, err := Direct(options.EqualParts(2, options.MeasureDistance))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(segments))
+	assert.Equal(t, 6, len(segments[0].Points))
+	assert.Equal(t, 6, len(segments[1].Points))
+	assert.Equal(t, segments[0].Points[5], segments[1].Points[0])
+
+	// 20 minutes in total; the point after 11 minutes lies closest to 10 minutes
+	segments, err = Direct(options.EqualParts(2, options.MeasureDuration))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(segments))
+	assert.Equal(t, 8, len(segments[0].Points))
+	assert.Equal(t, 4, len(segments[1].Points))
+
+	segments, err = Direct(options.EqualParts(5, options.MeasurePoints))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(segments))
+	for _, part := range segments {
+		assert.Equal(t, 3, len(part.Points))
+	}
+
+	// at most one part per leg
+	segments, err = Direct(options.EqualParts(20, options.MeasurePoints))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(segments))
+}
+
+func TestDirectClimbSplit(t *testing.T) {
+	segment := elevationSegment(100, 105, 140, 138, 145, 110, 100, 102, 98, 130, 125)
+	segments, err := Direct(options.ClimbSplit(30 * unit.Metre))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(segments))
+	assert.Equal(t, 5, len(segments[0].Points))
+	assert.Equal(t, 5, len(segments[1].Points))
+	assert.Equal(t, 3, len(segments[2].Points))
+	assert.Equal(t, segments[0].Points[4], segments[1].Points[0])
+
+	// without significant extrema, segments are not split
+	segments, err = Direct(options.ClimbSplit(100 * unit.Metre))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(segments))
+}
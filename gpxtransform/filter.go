@@ -32,3 +32,38 @@ func Filter(filterOptions ...options.FilterOptions) config.GPXSegmentTransform {
 		return []gpx.GPXTrackSegment{gpx.GPXTrackSegment{Points: points, Extensions: trackSegment.Extensions}}, err
 	}
 }
+
+/*
+FilterParts creates a "GPXSegmentTransform"er that splits a segment based on
+splitOptions and filters the points of every part based on filterOptions.
+Parts that lose all their points by filtering are removed, e.g., the parts of
+a segment that lie outside of an area to keep. Segments without points are
+returned unchanged, as by Filter.
+*/
+func FilterParts(splitOptions []options.SplitOptions, filterOptions ...options.FilterOptions) config.GPXSegmentTransform {
+	split := Split(splitOptions...)
+	filter := Filter(filterOptions...)
+	return func(trackSegment gpx.GPXTrackSegment) (segments []gpx.GPXTrackSegment, err error) {
+		if len(trackSegment.Points) == 0 {
+			return []gpx.GPXTrackSegment{trackSegment}, nil
+		}
+		parts, err := split(trackSegment)
+		if err != nil {
+			return
+		}
+		segments = []gpx.GPXTrackSegment{}
+		for partIndex, _ := range parts {
+			var filtered []gpx.GPXTrackSegment
+			filtered, err = filter(parts[partIndex])
+			if err != nil {
+				return
+			}
+			for _, segment := range filtered {
+				if len(segment.Points) != 0 {
+					segments = append(segments, segment)
+				}
+			}
+		}
+		return
+	}
+}
@@ -2,6 +2,7 @@ package gpxtransform
 
 import (
 	"testing"
+	"time"
 
 	"github.com/abzicht/gpsplit/gpxtransform/config"
 	"github.com/abzicht/gpsplit/gpxtransform/options"
@@ -43,3 +44,157 @@ func TestFilterTrimEnd(t *testing.T) {
 	assert.Equal(t, 1, len(gpxFiles[0].Tracks[0].Segments[0].Points))
 
 }
+
+const gpxDataFix = `<?xml version="1.0"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+<trk>
+  <trkseg>
+    <trkpt lat="50.87551" lon="-1.28259">
+      <fix>2d</fix>
+      <sat>3</sat>
+      <hdop>12.5</hdop>
+    </trkpt>
+    <trkpt lat="50.87545" lon="-1.28237">
+      <fix>3d</fix>
+      <sat>7</sat>
+      <hdop>1.2</hdop>
+    </trkpt>
+    <trkpt lat="50.87533" lon="-1.28199">
+      <fix>3d</fix>
+      <sat>9</sat>
+      <hdop>0.9</hdop>
+    </trkpt>
+    <trkpt lat="50.87522" lon="-1.28158">
+    </trkpt>
+  </trkseg>
+</trk>
+</gpx>
+`
+
+func TestFilterFixQuality(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataFix))
+	assert.NoError(t, err)
+	tc := config.NewTransformConfig(config.WithSegmentTransform(Filter(options.MaxHorizontalDilution(2))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Filter(options.MinSatellites(8))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Filter(options.FixType("3d", "dgps"))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+}
+
+const gpxDataArea = `<?xml version="1.0"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+<trk>
+  <trkseg>
+    <trkpt lat="50.0001" lon="8.0001"></trkpt>
+    <trkpt lat="50.0002" lon="8.0002"></trkpt>
+    <trkpt lat="50.0100" lon="8.0100"></trkpt>
+    <trkpt lat="50.0003" lon="8.0003"></trkpt>
+    <trkpt lat="50.0004" lon="8.0004"></trkpt>
+  </trkseg>
+</trk>
+</gpx>
+`
+
+func TestFilterArea(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataArea))
+	assert.NoError(t, err)
+	area := options.BoundingBox{MinLatitude: 50, MinLongitude: 8, MaxLatitude: 50.001, MaxLongitude: 8.001}
+	tc := config.NewTransformConfig(config.WithSegmentTransform(Filter(options.KeepInside(area))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(gpxFiles[0].Tracks[0].Segments))
+	assert.Equal(t, 4, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Filter(options.DropInside(area))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+
+	tc = config.NewTransformConfig(config.WithSegmentTransform(FilterParts([]options.SplitOptions{options.AreaSplit(area)}, options.KeepInside(area))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments))
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments[1].Points))
+
+	// segments that were empty before are kept, as by Filter
+	segments, err := FilterParts([]options.SplitOptions{options.AreaSplit(area)}, options.KeepInside(area))(gpx.GPXTrackSegment{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(segments))
+	assert.Equal(t, 0, len(segments[0].Points))
+}
+
+func TestFilterPrivacyZone(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataArea))
+	assert.NoError(t, err)
+	zone := options.Circle{Center: gpx.Point{Latitude: 50.0100, Longitude: 8.0100}, Radius: 100 * unit.Metre}
+	tc := config.NewTransformConfig(config.WithSegmentTransform(FilterParts([]options.SplitOptions{options.AreaSplit(zone)}, options.DropInside(zone))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments))
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments[1].Points))
+
+	// trimming before splitting only affects the start of the original segment
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Chain(
+		Filter(options.TrimStart(20*unit.Metre)),
+		FilterParts([]options.SplitOptions{options.AreaSplit(zone)}, options.DropInside(zone)),
+	)))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments))
+	assert.Equal(t, 1, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments[1].Points))
+
+	for i := 0; i < 100; i++ {
+		randomZone := zone.Randomize(500 * unit.Metre)
+		assert.True(t, randomZone.Contains(gpx.Point{Latitude: 50.0100, Longitude: 8.0100}))
+		assert.True(t, randomZone.Contains(gpx.Point{Latitude: 50.0108, Longitude: 8.0100}))
+	}
+}
+
+func TestFilterTime(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	// 10:00 in Berlin equals 08:00 UTC
+	tc := config.NewTransformConfig(config.WithSegmentTransform(Filter(options.KeepTime(options.TimeOfDay(berlin, 10*time.Hour, 12*time.Hour)))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+	assert.Equal(t, 0, len(gpxFiles[0].Tracks[0].Segments[1].Points))
+
+	from := time.Date(2024, 5, 6, 7, 59, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 6, 8, 1, 0, 0, time.UTC)
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Filter(options.DropTime(options.TimeRange(from, to)))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+}
+
+func TestDirectSegmentTime(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	weekdays := options.Weekdays(time.UTC, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	tc := config.NewTransformConfig(config.WithSegmentTransform(Direct(options.KeepSegmentTime(weekdays))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(gpxFiles[0].Tracks[0].Segments))
+	assert.Equal(t, 6, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Direct(options.DropSegmentTime(weekdays))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(gpxFiles[0].Tracks[0].Segments))
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+}
@@ -0,0 +1,114 @@
+package gpxtransform
+
+import (
+	"math"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+fixtureStart is the time at which all generated test segments start.
+*/
+var fixtureStart = time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+
+/*
+fixturePoint returns a point at the provided latitude and longitude 8 that was
+recorded offset after fixtureStart.
+*/
+func fixturePoint(latitude float64, offset time.Duration) gpx.GPXPoint {
+	return gpx.GPXPoint{Point: gpx.Point{Latitude: latitude, Longitude: 8}, Timestamp: fixtureStart.Add(offset)}
+}
+
+const gpxDataTime = `<?xml version="1.0"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+<trk>
+  <trkseg>
+    <trkpt lat="50.0000" lon="8.0000"><ele>100</ele><time>2024-05-06T07:58:00Z</time></trkpt>
+    <trkpt lat="50.0010" lon="8.0000"><ele>101</ele><time>2024-05-06T07:59:00Z</time></trkpt>
+    <trkpt lat="50.0020" lon="8.0000"><ele>102</ele><time>2024-05-06T08:00:00Z</time></trkpt>
+    <trkpt lat="50.0030" lon="8.0000"><ele>103</ele><time>2024-05-06T08:01:00Z</time></trkpt>
+    <trkpt lat="50.0040" lon="8.0000"><ele>104</ele><time>2024-05-06T08:02:00Z</time></trkpt>
+    <trkpt lat="50.0050" lon="8.0000"><ele>105</ele><time>2024-05-06T08:03:00Z</time></trkpt>
+  </trkseg>
+  <trkseg>
+    <trkpt lat="50.0050" lon="8.0000"><ele>105</ele><time>2024-05-11T17:00:00Z</time></trkpt>
+    <trkpt lat="50.0040" lon="8.0000"><ele>104</ele><time>2024-05-11T17:01:00Z</time></trkpt>
+  </trkseg>
+</trk>
+</gpx>
+`
+
+/*
+elevationSegment returns a segment whose points hold the provided elevations.
+*/
+func elevationSegment(elevations ...float64) gpx.GPXTrackSegment {
+	segment := gpx.GPXTrackSegment{}
+	for i, elevation := range elevations {
+		point := gpx.GPXPoint{Point: gpx.Point{Latitude: 50 + float64(i)*0.0001, Longitude: 8}}
+		point.Elevation.SetValue(elevation)
+		segment.Points = append(segment.Points, point)
+	}
+	return segment
+}
+
+/*
+jitteredSegment returns a segment that heads north with 1.5 m/s, one point per
+second, where every other point is displaced to the east or west.
+*/
+func jitteredSegment() gpx.GPXTrackSegment {
+	segment := gpx.GPXTrackSegment{}
+	for i := 0; i < 60; i++ {
+		jitter := 0.00004
+		if i%2 == 0 {
+			jitter = -jitter
+		}
+		segment.Points = append(segment.Points, gpx.GPXPoint{
+			Point:     gpx.Point{Latitude: 50 + float64(i)*0.0000135, Longitude: 8 + jitter},
+			Timestamp: fixtureStart.Add(time.Duration(i) * time.Second),
+		})
+	}
+	return segment
+}
+
+/*
+circuit returns a segment of laps on a circle with a radius of 100 meters
+around 50,8 that start and end at its easternmost point. Every lap holds 36
+points and runs counterclockwise (1) or clockwise (-1).
+*/
+func circuit(directions ...int) gpx.GPXTrackSegment {
+	segment := gpx.GPXTrackSegment{}
+	angle := 0.0
+	point := func() gpx.GPXPoint {
+		return gpx.GPXPoint{Point: gpx.Point{
+			Latitude:  50 + 100*math.Sin(angle)/111195,
+			Longitude: 8 + 100*math.Cos(angle)/(111195*math.Cos(50*math.Pi/180)),
+		}}
+	}
+	segment.Points = append(segment.Points, point())
+	for _, direction := range directions {
+		for i := 0; i < 36; i++ {
+			angle += float64(direction) * 2 * math.Pi / 36
+			segment.Points = append(segment.Points, point())
+		}
+	}
+	return segment
+}
+
+/*
+journey returns a segment heading north with a point every 5 seconds, where
+every speed (in m/s) is kept for 10 minutes.
+*/
+func journey(speeds ...float64) gpx.GPXTrackSegment {
+	segment := gpx.GPXTrackSegment{}
+	latitude, offset := 50.0, time.Duration(0)
+	segment.Points = append(segment.Points, fixturePoint(latitude, 0))
+	for _, speed := range speeds {
+		for i := 0; i < 120; i++ {
+			latitude += 5 * speed / 111195
+			offset += 5 * time.Second
+			segment.Points = append(segment.Points, fixturePoint(latitude, offset))
+		}
+	}
+	return segment
+}
@@ -0,0 +1,42 @@
+package gpxtransform
+
+import (
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+TagMode creates a "GPXTrackTransform"er that sets the type of a track to the
+mode of transport in which most of its time was spent (cf.
+options.ClassifyModes), e.g., "cycling". Tracks without timestamps keep their
+type.
+*/
+func TagMode(window time.Duration) config.GPXTrackTransform {
+	return func(gpxTrack gpx.GPXTrack) (tracks []gpx.GPXTrack, err error) {
+		durations := map[options.Mode]time.Duration{}
+		classified := false
+		for _, segment := range gpxTrack.Segments {
+			segmentDurations, ok := options.ModeDurations(segment, window)
+			if !ok {
+				continue
+			}
+			classified = true
+			for mode, duration := range segmentDurations {
+				durations[mode] += duration
+			}
+		}
+		if classified {
+			dominant := options.Stationary
+			for _, mode := range []options.Mode{options.Stationary, options.Walking, options.Cycling, options.Motorized} {
+				if durations[mode] > durations[dominant] {
+					dominant = mode
+				}
+			}
+			gpxTrack.Type = dominant.String()
+		}
+		return []gpx.GPXTrack{gpxTrack}, nil
+	}
+}
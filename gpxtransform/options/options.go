@@ -21,3 +21,16 @@ NewTransformOptions returns a new TransformOptions object that holds the provide
 func NewTransformOptions(do func(gpx.GPXTrackSegment, int) (bool, error)) TransformOptions {
 	return TransformOptions{do}
 }
+
+/*
+hasTimestamps returns true iff all points of the segment hold a timestamp.
+Note that gpx.GPXTrackSegment.HasTimes is not implemented by gpxgo.
+*/
+func hasTimestamps(segment gpx.GPXTrackSegment) bool {
+	for _, point := range segment.Points {
+		if point.Timestamp.IsZero() {
+			return false
+		}
+	}
+	return true
+}
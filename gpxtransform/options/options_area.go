@@ -0,0 +1,137 @@
+package options
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+earthRadius is the mean radius of the earth, as used by gpxgo.
+*/
+const earthRadius = 6371 * unit.Kilo * unit.Metre
+
+/*
+Area describes a geographic region, e.g., a bounding box or a polygon.
+*/
+type Area interface {
+	/*
+		Contains must return true iff the provided point lies within the area.
+	*/
+	Contains(point gpx.Point) bool
+}
+
+/*
+BoundingBox is an Area that is bounded by minimal and maximal latitudes and longitudes.
+*/
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+func (b BoundingBox) Contains(point gpx.Point) bool {
+	return b.MinLatitude <= point.Latitude && point.Latitude <= b.MaxLatitude &&
+		b.MinLongitude <= point.Longitude && point.Longitude <= b.MaxLongitude
+}
+
+/*
+Polygon is an Area that is bounded by rings of points. The first ring is the
+exterior boundary, all following rings are holes within the exterior.
+Rings are treated as closed, i.e., the last point connects to the first one.
+*/
+type Polygon struct {
+	Rings [][]gpx.Point
+}
+
+func (p Polygon) Contains(point gpx.Point) bool {
+	if len(p.Rings) == 0 || !ringContains(p.Rings[0], point) {
+		return false
+	}
+	for _, hole := range p.Rings[1:] {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ringContains performs a ray casting test, treating latitude and longitude as
+planar coordinates.
+*/
+func ringContains(ring []gpx.Point, point gpx.Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Latitude > point.Latitude) != (b.Latitude > point.Latitude) {
+			crossing := (b.Longitude-a.Longitude)*(point.Latitude-a.Latitude)/(b.Latitude-a.Latitude) + a.Longitude
+			if point.Longitude < crossing {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+/*
+MultiArea is an Area that contains a point iff any of its areas contains it.
+*/
+type MultiArea []Area
+
+func (m MultiArea) Contains(point gpx.Point) bool {
+	for _, area := range m {
+		if area.Contains(point) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Circle is an Area that contains all points within Radius of Center.
+*/
+type Circle struct {
+	Center gpx.Point
+	Radius unit.Length
+}
+
+func (c Circle) Contains(point gpx.Point) bool {
+	return c.Center.Distance2D(&point) <= float64(c.Radius)
+}
+
+/*
+Randomize returns a circle that is larger by a random value between 0 and
+maxExtra and whose center is moved randomly, such that the returned circle
+still covers the original circle. This way, the original center cannot be
+inferred from the boundary of the returned circle.
+*/
+func (c Circle) Randomize(maxExtra unit.Length) Circle {
+	extra := unit.Length(rand.Float64()) * maxExtra
+	shift := unit.Length(rand.Float64()) * extra
+	return Circle{
+		Center: destination(c.Center, shift, rand.Float64()*360),
+		Radius: c.Radius + extra,
+	}
+}
+
+/*
+destination returns the point that is reached when traveling distance from
+start along a great circle with the initial bearing (in degrees from north).
+*/
+func destination(start gpx.Point, distance unit.Length, bearing float64) gpx.Point {
+	angularDistance := float64(distance / earthRadius)
+	lat1 := gpx.ToRad(start.Latitude)
+	lon1 := gpx.ToRad(start.Longitude)
+	theta := gpx.ToRad(bearing)
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angularDistance) + math.Cos(lat1)*math.Sin(angularDistance)*math.Cos(theta))
+	lon2 := lon1 + math.Atan2(math.Sin(theta)*math.Sin(angularDistance)*math.Cos(lat1), math.Cos(angularDistance)-math.Sin(lat1)*math.Sin(lat2))
+	return gpx.Point{
+		Latitude:  lat2 * 180 / math.Pi,
+		Longitude: math.Mod(lon2*180/math.Pi+540, 360) - 180,
+		Elevation: start.Elevation,
+	}
+}
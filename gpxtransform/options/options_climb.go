@@ -0,0 +1,232 @@
+package options
+
+import (
+	"math"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+Extremum is a summit or a valley of a segment.
+*/
+type Extremum struct {
+	// Index is the index of the extremum's point within the segment
+	Index  int
+	Summit bool
+}
+
+/*
+FindExtrema returns the significant summits and valleys of a segment in their
+order. Summits and valleys alternate. A summit is significant, if the elevation
+drops by at least prominence on both of its sides before rising above the
+summit again (or before the segment starts or ends); valleys likewise.
+The first and the last point of a segment are never returned. Points without
+elevation are ignored.
+*/
+func FindExtrema(segment gpx.GPXTrackSegment, prominence unit.Length) []Extremum {
+	const (
+		flat = iota
+		up
+		down
+	)
+	extrema := []Extremum{}
+	trend := flat
+	first, high, low, candidate := -1, -1, -1, -1
+	elevation := func(i int) float64 {
+		return segment.Points[i].Elevation.Value()
+	}
+	for i, point := range segment.Points {
+		if point.Elevation.Null() {
+			continue
+		}
+		e := point.Elevation.Value()
+		switch trend {
+		case flat:
+			if first == -1 {
+				first, high, low = i, i, i
+				continue
+			}
+			if e > elevation(high) {
+				high = i
+			}
+			if e < elevation(low) {
+				low = i
+			}
+			if elevation(high)-elevation(low) < float64(prominence) {
+				continue
+			}
+			if low < high {
+				if low != first {
+					extrema = append(extrema, Extremum{Index: low, Summit: false})
+				}
+				trend, candidate = up, high
+			} else {
+				if high != first {
+					extrema = append(extrema, Extremum{Index: high, Summit: true})
+				}
+				trend, candidate = down, low
+			}
+		case up:
+			if e > elevation(candidate) {
+				candidate = i
+			} else if elevation(candidate)-e >= float64(prominence) {
+				extrema = append(extrema, Extremum{Index: candidate, Summit: true})
+				trend, candidate = down, i
+			}
+		case down:
+			if e < elevation(candidate) {
+				candidate = i
+			} else if e-elevation(candidate) >= float64(prominence) {
+				extrema = append(extrema, Extremum{Index: candidate, Summit: false})
+				trend, candidate = up, i
+			}
+		}
+	}
+	return extrema
+}
+
+/*
+ClimbSplit divides a segment at its significant summits and valleys (cf.
+FindExtrema) into climbs and descents. Consecutive parts share their summit or
+valley.
+*/
+func ClimbSplit(prominence unit.Length) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			boundaries := []int{}
+			for _, extremum := range FindExtrema(segment, prominence) {
+				boundaries = append(boundaries, extremum.Index)
+			}
+			return cutAt(segment, boundaries), nil
+		},
+	}
+}
+
+/*
+maxGradeDistance is the minimal distance over which the maximal grade of a
+climb is measured, so that elevation noise between close points does not
+create steep grades.
+*/
+const maxGradeDistance = 100 * unit.Metre
+
+/*
+Climb describes a climb from a valley to a summit of a segment.
+*/
+type Climb struct {
+	// Start and End are the indices of the climb's first and last point
+	Start, End int
+	Length     unit.Length
+	Gain       unit.Length
+	// AverageGrade and MaxGrade are given in percent
+	AverageGrade float64
+	MaxGrade     float64
+}
+
+/*
+Category returns the category of the climb similar to those of cycling races,
+i.e., "HC" (hors catégorie) for the hardest climbs, followed by "1" to "4". The
+category is based on the climb's length multiplied with its average grade.
+Climbs with an average grade below 3% or a small score are not categorized
+("-").
+*/
+func (c Climb) Category() string {
+	score := float64(c.Length) * c.AverageGrade
+	switch {
+	case c.AverageGrade < 3 || score < 8000:
+		return "-"
+	case score >= 80000:
+		return "HC"
+	case score >= 64000:
+		return "1"
+	case score >= 32000:
+		return "2"
+	case score >= 16000:
+		return "3"
+	default:
+		return "4"
+	}
+}
+
+/*
+FindClimbs returns all climbs of a segment that gain at least prominence, each
+leading from a valley to the next summit (cf. FindExtrema).
+*/
+func FindClimbs(segment gpx.GPXTrackSegment, prominence unit.Length) []Climb {
+	valid := []int{}
+	for i, point := range segment.Points {
+		if point.Elevation.NotNull() {
+			valid = append(valid, i)
+		}
+	}
+	if len(valid) < 2 {
+		return []Climb{}
+	}
+	boundaries := []int{valid[0]}
+	for _, extremum := range FindExtrema(segment, prominence) {
+		boundaries = append(boundaries, extremum.Index)
+	}
+	boundaries = append(boundaries, valid[len(valid)-1])
+	elevation := func(i int) float64 {
+		return segment.Points[i].Elevation.Value()
+	}
+	climbs := []Climb{}
+	for b := 0; b < len(boundaries)-1; b++ {
+		// at the start and the end of a segment, climbs may begin after the
+		// first or end before the last point
+		start, end := boundaries[b], boundaries[b+1]
+		for _, i := range valid {
+			if i < boundaries[b] || i > boundaries[b+1] {
+				continue
+			}
+			if elevation(i) < elevation(start) {
+				start = i
+			}
+			if elevation(i) > elevation(end) {
+				end = i
+			}
+		}
+		if start >= end || elevation(end)-elevation(start) < float64(prominence) {
+			continue
+		}
+		climbs = append(climbs, newClimb(segment, start, end))
+	}
+	return climbs
+}
+
+/*
+newClimb computes the statistics of the climb from point start to point end.
+*/
+func newClimb(segment gpx.GPXTrackSegment, start, end int) Climb {
+	points := segment.Points
+	// distances[i] is the distance from start to point start+i
+	distances := make([]float64, end-start+1)
+	for i := start + 1; i <= end; i++ {
+		distances[i-start] = distances[i-start-1] + points[i-1].Distance2D(&points[i])
+	}
+	climb := Climb{
+		Start:  start,
+		End:    end,
+		Length: unit.Length(distances[len(distances)-1]),
+		Gain:   unit.Length(points[end].Elevation.Value() - points[start].Elevation.Value()),
+	}
+	if climb.Length > 0 {
+		climb.AverageGrade = 100 * float64(climb.Gain) / float64(climb.Length)
+	}
+	climb.MaxGrade = climb.AverageGrade
+	j := 0
+	for i := range distances {
+		if points[start+i].Elevation.Null() {
+			continue
+		}
+		for j < len(distances) && (distances[j]-distances[i] < float64(maxGradeDistance) || points[start+j].Elevation.Null()) {
+			j++
+		}
+		if j == len(distances) {
+			break
+		}
+		grade := 100 * (points[start+j].Elevation.Value() - points[start+i].Elevation.Value()) / (distances[j] - distances[i])
+		climb.MaxGrade = math.Max(climb.MaxGrade, grade)
+	}
+	return climb
+}
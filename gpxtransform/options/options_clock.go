@@ -0,0 +1,86 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+/*
+TimeMap maps a recorded timestamp onto its corrected timestamp.
+*/
+type TimeMap func(timestamp time.Time) time.Time
+
+/*
+ShiftTime shifts timestamps by the provided offset, e.g., to correct a device
+clock that was set incorrectly.
+*/
+func ShiftTime(offset time.Duration) TimeMap {
+	return func(timestamp time.Time) time.Time {
+		return timestamp.Add(offset)
+	}
+}
+
+/*
+ReinterpretTimeZone reads the wall clock of timestamps as local time of the
+provided location and returns the corresponding UTC time. This corrects
+devices that record local time, but label it as UTC.
+*/
+func ReinterpretTimeZone(location *time.Location) TimeMap {
+	return func(timestamp time.Time) time.Time {
+		return time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(),
+			timestamp.Hour(), timestamp.Minute(), timestamp.Second(), timestamp.Nanosecond(), location).UTC()
+	}
+}
+
+/*
+DriftCorrection corrects a device clock that runs too fast or too slow.
+Two reference points map recorded timestamps onto their actual timestamps;
+all other timestamps are scaled linearly, i.e., timestamps before, between,
+and after the references are corrected alike.
+*/
+func DriftCorrection(recorded1, actual1, recorded2, actual2 time.Time) (TimeMap, error) {
+	recordedSpan := recorded2.Sub(recorded1)
+	if recordedSpan == 0 {
+		return nil, errors.New(fmt.Sprintf("DriftCorrection: reference points must differ; got %v twice", recorded1))
+	}
+	factor := float64(actual2.Sub(actual1)) / float64(recordedSpan)
+	if factor <= 0 {
+		return nil, errors.New("DriftCorrection: reference points must be in the same order for recorded and actual times")
+	}
+	return func(timestamp time.Time) time.Time {
+		return actual1.Add(time.Duration(math.Round(factor * float64(timestamp.Sub(recorded1)))))
+	}, nil
+}
+
+/*
+gpsWeekRollover is the period after which the 10-bit week number of GPS
+receivers rolls over.
+*/
+const gpsWeekRollover = 1024 * 7 * 24 * time.Hour
+
+/*
+WeekRollover repairs timestamps of GPS receivers that suffer from the week
+number rollover bug and, hence, are shifted by multiples of 1024 weeks (about
+19.6 years). Every timestamp is shifted by the multiple of 1024 weeks that
+brings it closest to the provided reference, e.g., the (approximate) date of
+the recording or the modification time of the recorded file. Timestamps within
+512 weeks of the reference are not modified.
+*/
+func WeekRollover(reference time.Time) TimeMap {
+	return func(timestamp time.Time) time.Time {
+		rollovers := math.Round(float64(reference.Sub(timestamp)) / float64(gpsWeekRollover))
+		return timestamp.Add(time.Duration(rollovers) * gpsWeekRollover)
+	}
+}
+
+/*
+MirrorTime mirrors timestamps within the span from first to last, i.e., first
+becomes last and vice versa. Applied on reversed points, time increases again.
+*/
+func MirrorTime(first, last time.Time) TimeMap {
+	return func(timestamp time.Time) time.Time {
+		return first.Add(last.Sub(timestamp))
+	}
+}
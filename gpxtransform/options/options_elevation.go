@@ -0,0 +1,44 @@
+package options
+
+import (
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+ElevationModel provides terrain elevations, e.g., from a digital elevation model (DEM).
+*/
+type ElevationModel interface {
+	/*
+		Elevation returns the elevation in meters at the provided point. found
+		must be false, if the model holds no elevation for that point.
+	*/
+	Elevation(point gpx.Point) (elevation float64, found bool, err error)
+}
+
+/*
+ModelElevation replaces point elevations with the elevations of the provided
+model. If onlyMissing is true, only points without elevation are modified.
+Points that are not covered by the model keep their elevation.
+*/
+func ModelElevation(model ElevationModel, onlyMissing bool) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			points := make([]gpx.GPXPoint, len(segment.Points))
+			copy(points, segment.Points)
+			for i := range points {
+				if onlyMissing && points[i].Elevation.NotNull() {
+					continue
+				}
+				elevation, found, err := model.Elevation(points[i].Point)
+				if err != nil {
+					return nil, err
+				}
+				if found {
+					points[i].Elevation.SetValue(elevation)
+				}
+			}
+			segment.Points = points
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package options
 
 import (
+	"strings"
+
 	"github.com/tkrajina/gpxgo/gpx"
 	"gonum.org/v1/gonum/unit"
 )
@@ -61,3 +63,99 @@ func TrimEnd(maxRadius unit.Length) FilterOptions {
 		},
 	}
 }
+
+/*
+MaxHorizontalDilution cuts all points whose horizontal dilution of precision (HDOP) exceeds maxHdop.
+Points without HDOP information are kept.
+*/
+func MaxHorizontalDilution(maxHdop float64) FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			hdop := segment.Points[index].HorizontalDilution
+			return hdop.Null() || hdop.Value() <= maxHdop, nil
+		},
+	}
+}
+
+/*
+MaxVerticalDilution cuts all points whose vertical dilution of precision (VDOP) exceeds maxVdop.
+Points without VDOP information are kept.
+*/
+func MaxVerticalDilution(maxVdop float64) FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			vdop := segment.Points[index].VerticalDilution
+			return vdop.Null() || vdop.Value() <= maxVdop, nil
+		},
+	}
+}
+
+/*
+MaxPositionalDilution cuts all points whose positional dilution of precision (PDOP) exceeds maxPdop.
+Points without PDOP information are kept.
+*/
+func MaxPositionalDilution(maxPdop float64) FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			pdop := segment.Points[index].PositionalDilution
+			return pdop.Null() || pdop.Value() <= maxPdop, nil
+		},
+	}
+}
+
+/*
+MinSatellites cuts all points that were recorded with less than minSatellites satellites.
+Points without satellite information are kept.
+*/
+func MinSatellites(minSatellites int) FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			satellites := segment.Points[index].Satellites
+			return satellites.Null() || satellites.Value() >= minSatellites, nil
+		},
+	}
+}
+
+/*
+FixType cuts all points whose type of GPS fix is not among the provided fixTypes.
+Valid fix types (as defined by GPX 1.1) are none, 2d, 3d, dgps, and pps.
+Points without fix information are kept.
+*/
+func FixType(fixTypes ...string) FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			fix := segment.Points[index].TypeOfGpsFix
+			if len(fix) == 0 {
+				return true, nil
+			}
+			for _, fixType := range fixTypes {
+				if strings.EqualFold(fix, fixType) {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	}
+}
+
+/*
+KeepInside cuts all points that lie outside the provided area.
+*/
+func KeepInside(area Area) FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			return area.Contains(segment.Points[index].Point), nil
+		},
+	}
+}
+
+/*
+DropInside cuts all points that lie inside the provided area.
+*/
+func DropInside(area Area) FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			return !area.Contains(segment.Points[index].Point), nil
+		},
+	}
+}
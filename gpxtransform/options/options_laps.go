@@ -0,0 +1,118 @@
+package options
+
+import (
+	"errors"
+
+	"github.com/abzicht/gogenericfunc/fun"
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+LapSplit splits a segment into laps, i.e., whenever the track returns within
+radius of the reference point heading in the same direction (within 90°) as
+when it passed the reference for the first time. If no reference is provided,
+the first point of the segment is used. Every lap ends at the point that lies
+closest to the reference. A pass ends only once the track is more than twice
+the radius away from the reference, so that GPS noise near the reference does
+not create additional laps. If the segment does not start within radius of
+the reference, it is also split at the first pass, so that the approach is
+separated from the first lap.
+*/
+func LapSplit(reference fun.Option[gpx.Point], radius unit.Length) SplitOptions {
+	return SplitOptions{
+		DoSegment: func(segment gpx.GPXTrackSegment) ([]bool, error) {
+			splits := make([]bool, len(segment.Points))
+			if len(segment.Points) < 2 {
+				return splits, nil
+			}
+			center := reference.GetOrElse(segment.Points[0].Point)
+			xs, ys := project(segment.Points)
+			heading := func(i int) (float64, float64) {
+				if i == len(segment.Points)-1 {
+					i--
+				}
+				return xs[i+1] - xs[i], ys[i+1] - ys[i]
+			}
+			var firstX, firstY float64
+			passes := 0
+			inPass := false
+			passStart, closest, closestDistance := 0, 0, 0.0
+			endPass := func() {
+				inPass = false
+				dx, dy := heading(closest)
+				if passes == 0 {
+					firstX, firstY = dx, dy
+					splits[closest] = passStart != 0
+				} else {
+					splits[closest] = dx*firstX+dy*firstY > 0
+				}
+				passes++
+			}
+			for i, point := range segment.Points {
+				distance := point.Distance2D(&center)
+				if inPass {
+					if distance < closestDistance {
+						closest, closestDistance = i, distance
+					}
+					if distance > 2*float64(radius) {
+						endPass()
+					}
+				} else if distance <= float64(radius) {
+					inPass = true
+					passStart, closest, closestDistance = i, i, distance
+				}
+			}
+			if inPass && closest != len(segment.Points)-1 {
+				endPass()
+			}
+			splits[len(splits)-1] = false
+			return splits, nil
+		},
+	}
+}
+
+/*
+LineSplit splits a segment into laps at a start/finish line from a to b, i.e.,
+whenever the track crosses the line in the same direction as it crossed the
+line for the first time. The segment is split between the two points before
+and after the crossing, so the approach before the first crossing is
+separated from the first lap.
+*/
+func LineSplit(a, b gpx.Point) SplitOptions {
+	return SplitOptions{
+		DoSegment: func(segment gpx.GPXTrackSegment) ([]bool, error) {
+			if a.Distance2D(&b) == 0 {
+				return nil, errors.New("LineSplit: the start and the end of the line must differ")
+			}
+			splits := make([]bool, len(segment.Points))
+			points := append([]gpx.GPXPoint{{Point: a}, {Point: b}}, segment.Points...)
+			xs, ys := project(points)
+			lineX, lineY := xs[1], ys[1]
+			// side returns the side of the line (a, b) that the projected point i lies on
+			side := func(i int) float64 {
+				return lineX*ys[i] - lineY*xs[i]
+			}
+			direction := 0.0
+			for i := 2; i < len(points)-1; i++ {
+				s1, s2 := side(i), side(i+1)
+				if s1 == s2 || (s1 < 0) == (s2 < 0) {
+					continue
+				}
+				// check that the crossing lies between a and b
+				fraction := s1 / (s1 - s2)
+				x := xs[i] + fraction*(xs[i+1]-xs[i])
+				y := ys[i] + fraction*(ys[i+1]-ys[i])
+				position := (x*lineX + y*lineY) / (lineX*lineX + lineY*lineY)
+				if position < 0 || position > 1 {
+					continue
+				}
+				if direction == 0 {
+					direction = s2 - s1
+				}
+				splits[i-2] = (s2-s1)*direction > 0
+			}
+			return splits, nil
+		},
+	}
+}
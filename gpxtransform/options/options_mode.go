@@ -0,0 +1,195 @@
+package options
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+Mode is the mode of transport of an activity.
+*/
+type Mode int
+
+const (
+	Stationary Mode = iota
+	Walking
+	Cycling
+	Motorized
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Stationary:
+		return "stationary"
+	case Walking:
+		return "walking"
+	case Cycling:
+		return "cycling"
+	default:
+		return "motorized"
+	}
+}
+
+/*
+Thresholds for classifying modes (cf. ClassifyModes).
+*/
+const (
+	// stationarySpeed is the median speed (in m/s) below which points are stationary
+	stationarySpeed = 0.5
+	// walkingSpeed is the median speed (in m/s) below which points are walking
+	walkingSpeed = 2.5
+	// cyclingSpeed is the median speed (in m/s) above which points are motorized
+	cyclingSpeed = 8.5
+	// cyclingTopSpeed is the 95th percentile of speeds (in m/s) above which points are motorized
+	cyclingTopSpeed = 15.0
+	// cyclingAcceleration is the 75th percentile of absolute accelerations (in m/s²) above which points are motorized
+	cyclingAcceleration = 1.0
+)
+
+/*
+ClassifyModes returns the mode of transport of every point of a segment. Each
+point is classified by the speeds and accelerations within the window centered
+around it:
+stationary, if the median speed is below 0.5 m/s;
+walking, if the median speed is below 2.5 m/s;
+motorized, if the median speed exceeds 8.5 m/s, 5% of speeds exceed 15 m/s,
+or 25% of accelerations exceed 1 m/s²;
+cycling otherwise.
+Afterwards, modes that last shorter than window are merged into the preceding
+mode (or the following one at the start of the segment), so that short
+outliers do not change the mode. ok is false, if the segment has less than two
+points or not all points hold a timestamp.
+*/
+func ClassifyModes(segment gpx.GPXTrackSegment, window time.Duration) (modes []Mode, ok bool) {
+	points := segment.Points
+	if len(points) < 2 || !hasTimestamps(segment) {
+		return nil, false
+	}
+	// speeds[j] and durations[j] describe the leg from point j to point j+1
+	legs := len(points) - 1
+	speeds := make([]float64, legs)
+	durations := make([]float64, legs)
+	for j := 0; j < legs; j++ {
+		durations[j] = points[j+1].Timestamp.Sub(points[j].Timestamp).Seconds()
+		if durations[j] > 0 {
+			speeds[j] = points[j].Distance2D(&points[j+1]) / durations[j]
+		}
+	}
+	// middle returns the time of the middle of leg j relative to point i
+	middle := func(j, i int) time.Duration {
+		return points[j].Timestamp.Add(points[j+1].Timestamp.Sub(points[j].Timestamp) / 2).Sub(points[i].Timestamp)
+	}
+	modes = make([]Mode, len(points))
+	firstLeg := 0
+	for i := range points {
+		windowSpeeds, accelerations := []float64{}, []float64{}
+		for firstLeg < legs && middle(firstLeg, i) < -window/2 {
+			firstLeg++
+		}
+		for j := firstLeg; j < legs; j++ {
+			if middle(j, i) > window/2 {
+				break
+			}
+			windowSpeeds = append(windowSpeeds, speeds[j])
+			if j+1 < legs && durations[j]+durations[j+1] > 0 {
+				accelerations = append(accelerations, math.Abs(speeds[j+1]-speeds[j])/((durations[j]+durations[j+1])/2))
+			}
+		}
+		if len(windowSpeeds) == 0 {
+			// the legs around point i are longer than the window
+			if i > 0 {
+				windowSpeeds = append(windowSpeeds, speeds[i-1])
+			}
+			if i < legs {
+				windowSpeeds = append(windowSpeeds, speeds[i])
+			}
+		}
+		median := percentile(windowSpeeds, 0.5)
+		switch {
+		case median < stationarySpeed:
+			modes[i] = Stationary
+		case median < walkingSpeed:
+			modes[i] = Walking
+		case median > cyclingSpeed || percentile(windowSpeeds, 0.95) > cyclingTopSpeed || percentile(accelerations, 0.75) > cyclingAcceleration:
+			modes[i] = Motorized
+		default:
+			modes[i] = Cycling
+		}
+	}
+	// merge short runs
+	for merged := true; merged; {
+		merged = false
+		for start := 0; start < len(modes); {
+			end := start
+			for end+1 < len(modes) && modes[end+1] == modes[start] {
+				end++
+			}
+			if (start > 0 || end < len(modes)-1) && points[end].Timestamp.Sub(points[start].Timestamp) < window {
+				replacement := modes[end+1]
+				if start > 0 {
+					replacement = modes[start-1]
+				}
+				for k := start; k <= end; k++ {
+					modes[k] = replacement
+				}
+				merged = true
+			}
+			start = end + 1
+		}
+	}
+	return modes, true
+}
+
+/*
+percentile returns the p-th percentile (0 <= p <= 1) of values using the
+nearest rank. It returns 0 for no values.
+*/
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted[max(0, int(math.Ceil(p*float64(len(sorted))))-1)]
+}
+
+/*
+ModeDurations returns the time spent in every mode of transport (cf.
+ClassifyModes). The time between two points is attributed to the mode of the
+first point. ok is false, if no mode could be classified.
+*/
+func ModeDurations(segment gpx.GPXTrackSegment, window time.Duration) (durations map[Mode]time.Duration, ok bool) {
+	modes, ok := ClassifyModes(segment, window)
+	if !ok {
+		return
+	}
+	durations = map[Mode]time.Duration{}
+	for i := 0; i < len(modes)-1; i++ {
+		durations[modes[i]] += segment.Points[i+1].Timestamp.Sub(segment.Points[i].Timestamp)
+	}
+	return durations, true
+}
+
+/*
+ModeSplit splits whenever the mode of transport changes, e.g., between a bike
+ride and a train ride (cf. ClassifyModes for the classification and the role
+of window). Segments without timestamps are not split.
+*/
+func ModeSplit(window time.Duration) SplitOptions {
+	return SplitOptions{
+		DoSegment: func(segment gpx.GPXTrackSegment) ([]bool, error) {
+			splits := make([]bool, len(segment.Points))
+			modes, ok := ClassifyModes(segment, window)
+			if !ok {
+				return splits, nil
+			}
+			for i := 0; i < len(modes)-1; i++ {
+				splits[i] = modes[i] != modes[i+1]
+			}
+			return splits, nil
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+Measure describes how the size of (a part of) a segment is measured.
+*/
+type Measure int
+
+const (
+	MeasureDistance Measure = iota
+	MeasureDuration
+	MeasurePoints
+)
+
+/*
+EqualParts divides a segment into the provided number of parts of equal
+distance, duration, or point count (cf. Measure). Parts are cut at the points
+that lie closest to the ideal boundaries, so their sizes are only equal up to
+the spacing of points. Consecutive parts share their boundary point, such that
+no distance or duration is lost between them. Segments with too few points
+are divided into fewer parts. Segments without timestamps are not divided by
+duration.
+*/
+func EqualParts(parts int, measure Measure) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if parts < 1 {
+				return nil, errors.New(fmt.Sprintf("EqualParts: at least one part is required; got %v", parts))
+			}
+			if len(segment.Points) < 3 || parts == 1 {
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			if measure == MeasureDuration && !hasTimestamps(segment) {
+				slog.Warn("EqualParts: segment has no timestamps and is not divided by duration")
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			sums := make([]float64, len(segment.Points))
+			for i := 1; i < len(segment.Points); i++ {
+				a, b := segment.Points[i-1], segment.Points[i]
+				switch measure {
+				case MeasureDistance:
+					sums[i] = sums[i-1] + a.Distance2D(&b)
+				case MeasureDuration:
+					sums[i] = sums[i-1] + b.Timestamp.Sub(a.Timestamp).Seconds()
+				default:
+					sums[i] = float64(i)
+				}
+			}
+			total := sums[len(sums)-1]
+			boundaries := []int{}
+			index := 0
+			for part := 1; part < parts; part++ {
+				target := total * float64(part) / float64(parts)
+				for index < len(sums)-1 && sums[index] < target {
+					index++
+				}
+				boundary := index
+				if boundary > 0 && target-sums[boundary-1] < sums[boundary]-target {
+					boundary--
+				}
+				boundaries = append(boundaries, boundary)
+			}
+			return cutAt(segment, boundaries), nil
+		},
+	}
+}
+
+/*
+cutAt divides a segment at the provided, ascending point indices. Consecutive
+parts share the point at which they were cut. Indices that would create parts
+of a single point are ignored.
+*/
+func cutAt(segment gpx.GPXTrackSegment, boundaries []int) []gpx.GPXTrackSegment {
+	segments := []gpx.GPXTrackSegment{}
+	start := 0
+	for _, boundary := range boundaries {
+		if boundary <= start || boundary >= len(segment.Points)-1 {
+			continue
+		}
+		part := segment
+		part.Points = segment.Points[start : boundary+1 : boundary+1]
+		segments = append(segments, part)
+		start = boundary
+	}
+	last := segment
+	last.Points = segment.Points[start:]
+	return append(segments, last)
+}
@@ -0,0 +1,239 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+TimeResample replaces the points of a segment with points every step, whose
+position and elevation are interpolated linearly between the original points.
+Resampled points lie at multiples of step (e.g., at full minutes for a step
+of 1m), so that segments of different devices share a common time base.
+If maxGap is not 0, no points are interpolated between two consecutive
+original points that are more than maxGap apart.
+Segments that do not span a multiple of step are removed, as no resampled
+point remains. Segments without timestamps are not modified.
+*/
+func TimeResample(step time.Duration, maxGap time.Duration) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if step <= 0 {
+				return nil, errors.New(fmt.Sprintf("TimeResample: step must be positive; got %v", step))
+			}
+			if len(segment.Points) == 0 {
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			if !hasTimestamps(segment) {
+				slog.Warn("TimeResample: segment has no timestamps and is not resampled")
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			points := []gpx.GPXPoint{}
+			sampleTime := ceilTime(segment.Points[0].Timestamp, step)
+			for i := 0; i < len(segment.Points)-1; i++ {
+				a, b := segment.Points[i], segment.Points[i+1]
+				if b.Timestamp.Before(a.Timestamp) {
+					return nil, errors.New(fmt.Sprintf("TimeResample: timestamps of points %v and %v are not in order", i, i+1))
+				}
+				if maxGap != 0 && b.Timestamp.Sub(a.Timestamp) > maxGap {
+					if !sampleTime.After(a.Timestamp) {
+						points = append(points, interpolate(a, b, 0))
+					}
+					sampleTime = ceilTime(b.Timestamp, step)
+					continue
+				}
+				for !sampleTime.After(b.Timestamp) {
+					fraction := 0.0
+					if b.Timestamp.After(a.Timestamp) {
+						fraction = float64(sampleTime.Sub(a.Timestamp)) / float64(b.Timestamp.Sub(a.Timestamp))
+					}
+					points = append(points, interpolate(a, b, fraction))
+					sampleTime = sampleTime.Add(step)
+				}
+			}
+			last := segment.Points[len(segment.Points)-1]
+			if len(segment.Points) == 1 && last.Timestamp.Equal(sampleTime) {
+				points = append(points, interpolate(last, last, 0))
+			}
+			if len(points) == 0 {
+				return []gpx.GPXTrackSegment{}, nil
+			}
+			segment.Points = points
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
+
+/*
+ceilTime rounds t up to the next multiple of step.
+*/
+func ceilTime(t time.Time, step time.Duration) time.Time {
+	truncated := t.Truncate(step)
+	if truncated.Before(t) {
+		return truncated.Add(step)
+	}
+	return truncated
+}
+
+/*
+interpolate returns a point between a (fraction 0) and b (fraction 1) whose
+position, elevation, and timestamp are interpolated linearly. The elevation is
+only set, if both points hold an elevation.
+*/
+func interpolate(a, b gpx.GPXPoint, fraction float64) gpx.GPXPoint {
+	point := gpx.GPXPoint{
+		Point: gpx.Point{
+			Latitude:  a.Latitude + fraction*(b.Latitude-a.Latitude),
+			Longitude: a.Longitude + fraction*(b.Longitude-a.Longitude),
+		},
+	}
+	if a.Elevation.NotNull() && b.Elevation.NotNull() {
+		point.Elevation.SetValue(a.Elevation.Value() + fraction*(b.Elevation.Value()-a.Elevation.Value()))
+	}
+	if !a.Timestamp.IsZero() && !b.Timestamp.IsZero() {
+		point.Timestamp = a.Timestamp.Add(time.Duration(fraction * float64(b.Timestamp.Sub(a.Timestamp))))
+	}
+	return point
+}
+
+/*
+DistanceResample replaces the points of a segment with points every spacing
+along the path, whose position, elevation, and timestamp are interpolated
+linearly between the original points. The first and the last point of the
+segment are kept, so the distance between the last two points may be shorter
+than spacing.
+*/
+func DistanceResample(spacing unit.Length) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if spacing <= 0 {
+				return nil, errors.New(fmt.Sprintf("DistanceResample: spacing must be positive; got %v", spacing))
+			}
+			if len(segment.Points) < 2 {
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			points := []gpx.GPXPoint{interpolate(segment.Points[0], segment.Points[0], 0)}
+			// the distance from the last resampled point to the current original point
+			travelled := 0.0
+			for i := 0; i < len(segment.Points)-1; i++ {
+				a, b := segment.Points[i], segment.Points[i+1]
+				legLength := a.Distance2D(&b)
+				next := float64(spacing) - travelled
+				for next <= legLength {
+					points = append(points, interpolate(a, b, next/legLength))
+					next += float64(spacing)
+				}
+				travelled = legLength - (next - float64(spacing))
+			}
+			last := segment.Points[len(segment.Points)-1]
+			if travelled > 0 {
+				points = append(points, interpolate(last, last, 0))
+			}
+			segment.Points = points
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
+
+/*
+gpsplitNamespace is the XML namespace of extensions that are added by gpsplit.
+*/
+const gpsplitNamespace gpx.NamespaceURL = "https://github.com/abzicht/gpsplit"
+
+/*
+syntheticExtension is the name of the extension that marks interpolated points.
+*/
+const syntheticExtension = "synthetic"
+
+/*
+greatCircleGap is the gap from which Densify interpolates positions along the
+great circle. For shorter gaps, linear interpolation deviates by less than a
+few meters from the great circle.
+*/
+const greatCircleGap = 10 * unit.Kilo * unit.Metre
+
+/*
+Densify inserts interpolated points between consecutive points that are more
+than maxStep apart, such that no two consecutive points are more than maxStep
+apart afterwards. Positions, elevations, and timestamps are interpolated
+linearly. Only for gaps longer than 10 km, e.g., of flights, positions are
+interpolated along the great circle. Inserted points are marked with a
+"synthetic" extension (cf. IsSynthetic and RemoveSynthetic).
+*/
+func Densify(maxStep unit.Length) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if maxStep <= 0 {
+				return nil, errors.New(fmt.Sprintf("Densify: maximal step must be positive; got %v", maxStep))
+			}
+			if len(segment.Points) < 2 {
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			points := []gpx.GPXPoint{segment.Points[0]}
+			for i := 0; i < len(segment.Points)-1; i++ {
+				a, b := segment.Points[i], segment.Points[i+1]
+				gap := a.Distance2D(&b)
+				parts := int(math.Ceil(gap / float64(maxStep)))
+				for part := 1; part < parts; part++ {
+					point := interpolate(a, b, float64(part)/float64(parts))
+					if gap > float64(greatCircleGap) {
+						point = interpolateGreatCircle(a, b, float64(part)/float64(parts))
+					}
+					point.Extensions.GetOrCreateNode(gpsplitNamespace, syntheticExtension).Data = "true"
+					points = append(points, point)
+				}
+				points = append(points, b)
+			}
+			segment.Points = points
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
+
+/*
+IsSynthetic returns true iff the point was inserted by gpsplit, e.g., by Densify.
+*/
+func IsSynthetic(point gpx.GPXPoint) bool {
+	node, found := point.Extensions.GetNode(gpx.AnyNamespace, syntheticExtension)
+	return found && strings.TrimSpace(node.Data) == "true"
+}
+
+/*
+RemoveSynthetic cuts all points that were inserted by gpsplit (cf. IsSynthetic).
+*/
+func RemoveSynthetic() FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			return !IsSynthetic(segment.Points[index]), nil
+		},
+	}
+}
+
+/*
+interpolateGreatCircle works like interpolate, but interpolates the position
+along the great circle between a and b.
+*/
+func interpolateGreatCircle(a, b gpx.GPXPoint, fraction float64) gpx.GPXPoint {
+	point := interpolate(a, b, fraction)
+	lat1, lon1 := gpx.ToRad(a.Latitude), gpx.ToRad(a.Longitude)
+	lat2, lon2 := gpx.ToRad(b.Latitude), gpx.ToRad(b.Longitude)
+	angularDistance := gpx.HaversineDistance(a.Latitude, a.Longitude, b.Latitude, b.Longitude) / float64(earthRadius)
+	if angularDistance == 0 {
+		return point
+	}
+	fa := math.Sin((1-fraction)*angularDistance) / math.Sin(angularDistance)
+	fb := math.Sin(fraction*angularDistance) / math.Sin(angularDistance)
+	x := fa*math.Cos(lat1)*math.Cos(lon1) + fb*math.Cos(lat2)*math.Cos(lon2)
+	y := fa*math.Cos(lat1)*math.Sin(lon1) + fb*math.Cos(lat2)*math.Sin(lon2)
+	z := fa*math.Sin(lat1) + fb*math.Sin(lat2)
+	point.Latitude = math.Atan2(z, math.Sqrt(x*x+y*y)) * 180 / math.Pi
+	point.Longitude = math.Atan2(y, x) * 180 / math.Pi
+	return point
+}
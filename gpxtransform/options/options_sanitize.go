@@ -0,0 +1,177 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+SanitizeOptions hold a function that removes or obscures information of a
+whole file, e.g., before it is published.
+*/
+type SanitizeOptions struct {
+	Do func(gpxFile gpx.GPX) (gpx.GPX, error)
+}
+
+/*
+sanitizedCreator replaces the creator of sanitized files, as GPX requires one.
+*/
+const sanitizedCreator = "gpsplit"
+
+/*
+StripMetadata removes information about the device, the creator, and the
+author of a file, i.e., its creator, author, links, keywords, and time, as
+well as the source of all tracks, routes, and points. Names, descriptions,
+and copyright are kept.
+*/
+func StripMetadata() SanitizeOptions {
+	return SanitizeOptions{
+		func(gpxFile gpx.GPX) (gpx.GPX, error) {
+			// MapPoints copies tracks and routes, so they can be modified safely
+			gpxFile = MapPoints(gpxFile, func(point gpx.GPXPoint) gpx.GPXPoint {
+				point.Source = ""
+				return point
+			})
+			gpxFile.Creator = sanitizedCreator
+			gpxFile.AuthorName = ""
+			gpxFile.AuthorEmail = ""
+			gpxFile.AuthorLink = ""
+			gpxFile.AuthorLinkText = ""
+			gpxFile.AuthorLinkType = ""
+			gpxFile.Link = ""
+			gpxFile.LinkText = ""
+			gpxFile.LinkType = ""
+			gpxFile.Keywords = ""
+			gpxFile.Time = nil
+			for trackIndex := range gpxFile.Tracks {
+				gpxFile.Tracks[trackIndex].Source = ""
+			}
+			for routeIndex := range gpxFile.Routes {
+				gpxFile.Routes[routeIndex].Source = ""
+			}
+			return gpxFile, nil
+		},
+	}
+}
+
+/*
+StripExtensions removes all extensions of a file, its tracks, segments,
+routes, and points, e.g., heart rate, cadence, or temperature recorded by
+sensors.
+*/
+func StripExtensions() SanitizeOptions {
+	return SanitizeOptions{
+		func(gpxFile gpx.GPX) (gpx.GPX, error) {
+			gpxFile = MapPoints(gpxFile, func(point gpx.GPXPoint) gpx.GPXPoint {
+				point.Extensions = gpx.Extension{}
+				return point
+			})
+			gpxFile.Extensions = gpx.Extension{}
+			gpxFile.MetadataExtensions = gpx.Extension{}
+			for trackIndex := range gpxFile.Tracks {
+				gpxFile.Tracks[trackIndex].Extensions = gpx.Extension{}
+				for segmentIndex := range gpxFile.Tracks[trackIndex].Segments {
+					gpxFile.Tracks[trackIndex].Segments[segmentIndex].Extensions = gpx.Extension{}
+				}
+			}
+			for routeIndex := range gpxFile.Routes {
+				gpxFile.Routes[routeIndex].Extensions = gpx.Extension{}
+			}
+			return gpxFile, nil
+		},
+	}
+}
+
+/*
+RoundCoordinates rounds latitudes and longitudes of all points to the provided
+number of decimals. 5 decimals correspond to about 1 meter, 4 decimals to
+about 10 meters.
+*/
+func RoundCoordinates(decimals int) SanitizeOptions {
+	return SanitizeOptions{
+		func(gpxFile gpx.GPX) (gpx.GPX, error) {
+			if decimals < 0 {
+				return gpxFile, errors.New(fmt.Sprintf("RoundCoordinates: decimals must not be negative; got %v", decimals))
+			}
+			factor := math.Pow(10, float64(decimals))
+			return MapPoints(gpxFile, func(point gpx.GPXPoint) gpx.GPXPoint {
+				point.Latitude = math.Round(point.Latitude*factor) / factor
+				point.Longitude = math.Round(point.Longitude*factor) / factor
+				return point
+			}), nil
+		},
+	}
+}
+
+/*
+RelativeTime shifts all timestamps of a file, such that its earliest timestamp
+equals epoch. Hence, durations and speeds are kept, while the date and time of
+the recording are hidden.
+*/
+func RelativeTime(epoch time.Time) SanitizeOptions {
+	return SanitizeOptions{
+		func(gpxFile gpx.GPX) (gpx.GPX, error) {
+			var earliest time.Time
+			MapPoints(gpxFile, func(point gpx.GPXPoint) gpx.GPXPoint {
+				if !point.Timestamp.IsZero() && (earliest.IsZero() || point.Timestamp.Before(earliest)) {
+					earliest = point.Timestamp
+				}
+				return point
+			})
+			if gpxFile.Time != nil && (earliest.IsZero() || gpxFile.Time.Before(earliest)) {
+				earliest = *gpxFile.Time
+			}
+			if earliest.IsZero() {
+				return gpxFile, nil
+			}
+			shift := ShiftTime(epoch.Sub(earliest))
+			if gpxFile.Time != nil {
+				fileTime := shift(*gpxFile.Time)
+				gpxFile.Time = &fileTime
+			}
+			return MapPoints(gpxFile, func(point gpx.GPXPoint) gpx.GPXPoint {
+				if !point.Timestamp.IsZero() {
+					point.Timestamp = shift(point.Timestamp)
+				}
+				return point
+			}), nil
+		},
+	}
+}
+
+/*
+MapPoints returns a copy of gpxFile, in which pointMap was applied on all
+track points, route points, and waypoints. gpxFile itself is not modified.
+*/
+func MapPoints(gpxFile gpx.GPX, pointMap func(point gpx.GPXPoint) gpx.GPXPoint) gpx.GPX {
+	mapPoints := func(points []gpx.GPXPoint) []gpx.GPXPoint {
+		mapped := make([]gpx.GPXPoint, len(points))
+		for i, point := range points {
+			mapped[i] = pointMap(point)
+		}
+		return mapped
+	}
+	gpxFile.Waypoints = mapPoints(gpxFile.Waypoints)
+	routes := make([]gpx.GPXRoute, len(gpxFile.Routes))
+	for routeIndex, route := range gpxFile.Routes {
+		route.Points = mapPoints(route.Points)
+		routes[routeIndex] = route
+	}
+	gpxFile.Routes = routes
+	tracks := make([]gpx.GPXTrack, len(gpxFile.Tracks))
+	for trackIndex, track := range gpxFile.Tracks {
+		segments := make([]gpx.GPXTrackSegment, len(track.Segments))
+		for segmentIndex, segment := range track.Segments {
+			segment.Points = mapPoints(segment.Points)
+			segments[segmentIndex] = segment
+		}
+		track.Segments = segments
+		tracks[trackIndex] = track
+	}
+	gpxFile.Tracks = tracks
+	return gpxFile
+}
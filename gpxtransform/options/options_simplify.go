@@ -0,0 +1,215 @@
+package options
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+Polyline simplification based on Visvalingam-Whyatt:
+Points are removed in order of the area (in m²) of the triangle that they form
+with their two adjacent points, as long as this area is smaller than minArea.
+Compared to DouglasPeucker, small details are removed more evenly.
+*/
+func VisvalingamWhyatt(minArea float64) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			segment.Points = visvalingam(segment.Points, func(area float64, remaining int) bool {
+				return area >= minArea
+			})
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
+
+/*
+SimplifyToPoints simplifies a segment until it holds at most maxPoints points.
+Points are removed in the order of Visvalingam-Whyatt (cf. VisvalingamWhyatt),
+i.e., the least significant points are removed first. The first and the last
+point of a segment are always kept, so maxPoints must be at least 2.
+*/
+func SimplifyToPoints(maxPoints int) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if maxPoints < 2 {
+				return nil, errors.New(fmt.Sprintf("SimplifyToPoints: at least 2 points must remain; got %v", maxPoints))
+			}
+			segment.Points = visvalingam(segment.Points, func(area float64, remaining int) bool {
+				return remaining <= maxPoints
+			})
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
+
+/*
+vwPoint is a point that may be removed by visvalingam.
+*/
+type vwPoint struct {
+	index      int
+	area       float64
+	prev, next int
+	removed    bool
+	heapIndex  int
+}
+
+type vwHeap []*vwPoint
+
+func (h vwHeap) Len() int { return len(h) }
+func (h vwHeap) Less(i, j int) bool {
+	if h[i].area == h[j].area {
+		return h[i].index < h[j].index
+	}
+	return h[i].area < h[j].area
+}
+func (h vwHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+func (h *vwHeap) Push(x any) {
+	p := x.(*vwPoint)
+	p.heapIndex = len(*h)
+	*h = append(*h, p)
+}
+func (h *vwHeap) Pop() any {
+	old := *h
+	p := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return p
+}
+
+/*
+visvalingam removes the point with the smallest effective area until stop
+returns true for the smallest effective area and the number of remaining
+points. The first and the last point are never removed.
+*/
+func visvalingam(points []gpx.GPXPoint, stop func(area float64, remaining int) bool) []gpx.GPXPoint {
+	if len(points) < 3 {
+		return points
+	}
+	xs, ys := project(points)
+	area := func(a, b, c int) float64 {
+		return math.Abs((xs[b]-xs[a])*(ys[c]-ys[a])-(xs[c]-xs[a])*(ys[b]-ys[a])) / 2
+	}
+	vw := make([]*vwPoint, len(points))
+	h := vwHeap{}
+	for i := range points {
+		vw[i] = &vwPoint{index: i, prev: i - 1, next: i + 1}
+		if i == 0 || i == len(points)-1 {
+			continue
+		}
+		vw[i].area = area(i-1, i, i+1)
+		heap.Push(&h, vw[i])
+	}
+	remaining := len(points)
+	for h.Len() > 0 {
+		smallest := h[0]
+		if stop(smallest.area, remaining) {
+			break
+		}
+		heap.Pop(&h)
+		smallest.removed = true
+		remaining--
+		prev, next := vw[smallest.prev], vw[smallest.next]
+		prev.next = next.index
+		next.prev = prev.index
+		// the effective area of neighbors must not drop below the area of
+		// the removed point, otherwise they would be removed out of order
+		for _, neighbor := range []*vwPoint{prev, next} {
+			if neighbor.index == 0 || neighbor.index == len(points)-1 {
+				continue
+			}
+			neighbor.area = math.Max(area(neighbor.prev, neighbor.index, neighbor.next), smallest.area)
+			heap.Fix(&h, neighbor.heapIndex)
+		}
+	}
+	simplified := []gpx.GPXPoint{}
+	for i, point := range points {
+		if !vw[i].removed {
+			simplified = append(simplified, point)
+		}
+	}
+	return simplified
+}
+
+/*
+project returns the positions of points in meters east (xs) and north (ys) of
+the first point.
+*/
+func project(points []gpx.GPXPoint) (xs, ys []float64) {
+	xs = make([]float64, len(points))
+	ys = make([]float64, len(points))
+	if len(points) == 0 {
+		return
+	}
+	origin := points[0].Point
+	scale := math.Cos(gpx.ToRad(origin.Latitude))
+	for i, point := range points {
+		xs[i] = (point.Longitude - origin.Longitude) * scale * metresPerDegree
+		ys[i] = (point.Latitude - origin.Latitude) * metresPerDegree
+	}
+	return
+}
+
+/*
+Time-aware polyline simplification based on Ramer-Douglas-Peucker with
+synchronized Euclidean distance (SED):
+Points are removed when lying within maxDistance of the position that is
+interpolated from the timestamps of the remaining adjacent points. Hence,
+removed points can be reconstructed from timestamps within maxDistance, and
+speeds and stops are preserved. Segments without timestamps are not modified.
+*/
+func SynchronizedDouglasPeucker(maxDistance unit.Length) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if len(segment.Points) < 3 {
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			if !hasTimestamps(segment) {
+				slog.Warn("SynchronizedDouglasPeucker: segment has no timestamps and is not simplified")
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			xs, ys := project(segment.Points)
+			keep := make([]bool, len(segment.Points))
+			keep[0], keep[len(keep)-1] = true, true
+			ranges := [][2]int{{0, len(segment.Points) - 1}}
+			for len(ranges) > 0 {
+				first, last := ranges[len(ranges)-1][0], ranges[len(ranges)-1][1]
+				ranges = ranges[:len(ranges)-1]
+				duration := segment.Points[last].Timestamp.Sub(segment.Points[first].Timestamp)
+				maxSED, maxIndex := 0.0, -1
+				for i := first + 1; i < last; i++ {
+					fraction := 0.0
+					if duration != 0 {
+						fraction = float64(segment.Points[i].Timestamp.Sub(segment.Points[first].Timestamp)) / float64(duration)
+					}
+					x := xs[first] + fraction*(xs[last]-xs[first])
+					y := ys[first] + fraction*(ys[last]-ys[first])
+					sed := math.Hypot(xs[i]-x, ys[i]-y)
+					if sed > maxSED {
+						maxSED, maxIndex = sed, i
+					}
+				}
+				if maxIndex != -1 && maxSED > float64(maxDistance) {
+					keep[maxIndex] = true
+					ranges = append(ranges, [2]int{first, maxIndex}, [2]int{maxIndex, last})
+				}
+			}
+			points := []gpx.GPXPoint{}
+			for i, point := range segment.Points {
+				if keep[i] {
+					points = append(points, point)
+				}
+			}
+			segment.Points = points
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
@@ -0,0 +1,254 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+metresPerDegree is the length of one degree of latitude.
+*/
+const metresPerDegree = float64(earthRadius) * math.Pi / 180
+
+/*
+Kalman smooths point positions with a constant-velocity Kalman filter.
+accelerationNoise (in m/s²) describes how strongly the velocity may change
+between two points; measurementNoise is the expected position error of a
+point with an HDOP of 1 (points without HDOP are assumed to have an HDOP of 1).
+If rts is true, a Rauch-Tung-Striebel backward pass is applied, i.e., every
+point is smoothed using both previous and following points.
+Segments without timestamps are not modified.
+*/
+func Kalman(accelerationNoise float64, measurementNoise unit.Length, rts bool) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if len(segment.Points) < 2 {
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			if !hasTimestamps(segment) {
+				slog.Warn("Kalman: segment has no timestamps and is not smoothed")
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			origin := segment.Points[0].Point
+			scale := math.Cos(gpx.ToRad(origin.Latitude))
+			east, north := project(segment.Points)
+			variances := make([]float64, len(segment.Points))
+			steps := make([]float64, len(segment.Points))
+			for i, point := range segment.Points {
+				sigma := float64(measurementNoise)
+				if point.HorizontalDilution.NotNull() {
+					sigma *= point.HorizontalDilution.Value()
+				}
+				variances[i] = sigma * sigma
+				if i > 0 {
+					steps[i] = point.Timestamp.Sub(segment.Points[i-1].Timestamp).Seconds()
+					if steps[i] < 0 {
+						return nil, errors.New(fmt.Sprintf("Kalman: timestamps of points %v and %v are not in order", i-1, i))
+					}
+				}
+			}
+			east = kalmanAxis(east, variances, steps, accelerationNoise, rts)
+			north = kalmanAxis(north, variances, steps, accelerationNoise, rts)
+			points := make([]gpx.GPXPoint, len(segment.Points))
+			copy(points, segment.Points)
+			for i := range points {
+				points[i].Latitude = origin.Latitude + north[i]/metresPerDegree
+				points[i].Longitude = origin.Longitude + east[i]/(scale*metresPerDegree)
+			}
+			segment.Points = points
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
+
+/*
+kalmanState holds position and velocity of a single axis and their covariance.
+*/
+type kalmanState struct {
+	x [2]float64
+	p [2][2]float64
+}
+
+/*
+predict propagates the state by dt seconds.
+*/
+func (s kalmanState) predict(dt, accelerationNoise float64) kalmanState {
+	q := accelerationNoise * accelerationNoise
+	return kalmanState{
+		x: [2]float64{s.x[0] + dt*s.x[1], s.x[1]},
+		p: [2][2]float64{
+			{s.p[0][0] + dt*(s.p[1][0]+s.p[0][1]) + dt*dt*s.p[1][1] + q*dt*dt*dt/3, s.p[0][1] + dt*s.p[1][1] + q*dt*dt/2},
+			{s.p[1][0] + dt*s.p[1][1] + q*dt*dt/2, s.p[1][1] + q*dt},
+		},
+	}
+}
+
+/*
+update corrects the state with a position measurement z of the given variance.
+*/
+func (s kalmanState) update(z, variance float64) kalmanState {
+	innovation := s.p[0][0] + variance
+	k0 := s.p[0][0] / innovation
+	k1 := s.p[1][0] / innovation
+	residual := z - s.x[0]
+	return kalmanState{
+		x: [2]float64{s.x[0] + k0*residual, s.x[1] + k1*residual},
+		p: [2][2]float64{
+			{(1 - k0) * s.p[0][0], (1 - k0) * s.p[0][1]},
+			{s.p[1][0] - k1*s.p[0][0], s.p[1][1] - k1*s.p[0][1]},
+		},
+	}
+}
+
+/*
+kalmanAxis filters the measurements of a single axis and returns the estimated
+positions. steps[i] holds the seconds between measurement i-1 and i.
+*/
+func kalmanAxis(measurements, variances, steps []float64, accelerationNoise float64, rts bool) []float64 {
+	n := len(measurements)
+	predicted := make([]kalmanState, n)
+	filtered := make([]kalmanState, n)
+	// the initial velocity is unknown, so we start with a large variance
+	predicted[0] = kalmanState{x: [2]float64{measurements[0], 0}, p: [2][2]float64{{variances[0], 0}, {0, 1e4}}}
+	filtered[0] = predicted[0].update(measurements[0], variances[0])
+	for i := 1; i < n; i++ {
+		predicted[i] = filtered[i-1].predict(steps[i], accelerationNoise)
+		filtered[i] = predicted[i].update(measurements[i], variances[i])
+	}
+	smoothed := filtered
+	if rts {
+		smoothed = make([]kalmanState, n)
+		smoothed[n-1] = filtered[n-1]
+		for i := n - 2; i >= 0; i-- {
+			smoothed[i] = rtsStep(filtered[i], predicted[i+1], smoothed[i+1], steps[i+1])
+		}
+	}
+	positions := make([]float64, n)
+	for i := range smoothed {
+		positions[i] = smoothed[i].x[0]
+	}
+	return positions
+}
+
+/*
+rtsStep performs a single Rauch-Tung-Striebel backward step.
+*/
+func rtsStep(filtered, predictedNext, smoothedNext kalmanState, dt float64) kalmanState {
+	// C = P_f * F^T * P_pred^-1
+	pf := filtered.p
+	pft := [2][2]float64{
+		{pf[0][0] + dt*pf[0][1], pf[0][1]},
+		{pf[1][0] + dt*pf[1][1], pf[1][1]},
+	}
+	pp := predictedNext.p
+	det := pp[0][0]*pp[1][1] - pp[0][1]*pp[1][0]
+	if det == 0 {
+		return filtered
+	}
+	inv := [2][2]float64{
+		{pp[1][1] / det, -pp[0][1] / det},
+		{-pp[1][0] / det, pp[0][0] / det},
+	}
+	c := mul2(pft, inv)
+	dx := [2]float64{smoothedNext.x[0] - predictedNext.x[0], smoothedNext.x[1] - predictedNext.x[1]}
+	dp := [2][2]float64{
+		{smoothedNext.p[0][0] - pp[0][0], smoothedNext.p[0][1] - pp[0][1]},
+		{smoothedNext.p[1][0] - pp[1][0], smoothedNext.p[1][1] - pp[1][1]},
+	}
+	cdp := mul2(mul2(c, dp), [2][2]float64{{c[0][0], c[1][0]}, {c[0][1], c[1][1]}})
+	return kalmanState{
+		x: [2]float64{
+			filtered.x[0] + c[0][0]*dx[0] + c[0][1]*dx[1],
+			filtered.x[1] + c[1][0]*dx[0] + c[1][1]*dx[1],
+		},
+		p: [2][2]float64{
+			{pf[0][0] + cdp[0][0], pf[0][1] + cdp[0][1]},
+			{pf[1][0] + cdp[1][0], pf[1][1] + cdp[1][1]},
+		},
+	}
+}
+
+func mul2(a, b [2][2]float64) [2][2]float64 {
+	return [2][2]float64{
+		{a[0][0]*b[0][0] + a[0][1]*b[1][0], a[0][0]*b[0][1] + a[0][1]*b[1][1]},
+		{a[1][0]*b[0][0] + a[1][1]*b[1][0], a[1][0]*b[0][1] + a[1][1]*b[1][1]},
+	}
+}
+
+/*
+MovingAverageElevation smooths elevations by replacing every elevation with
+the average of the window points centered around it. Near the segment's start
+and end, the window shrinks symmetrically. Points without elevation are
+neither smoothed nor used for smoothing.
+*/
+func MovingAverageElevation(window int) DirectOptions {
+	return elevationSmoothing(window, func(m int) []float64 {
+		coefficients := make([]float64, 2*m+1)
+		for i := range coefficients {
+			coefficients[i] = 1 / float64(2*m+1)
+		}
+		return coefficients
+	})
+}
+
+/*
+SavitzkyGolayElevation smooths elevations by fitting a quadratic polynomial to
+the window points centered around every point. Compared to
+MovingAverageElevation, summits and valleys are flattened less.
+Near the segment's start and end, the window shrinks symmetrically. Points
+without elevation are neither smoothed nor used for smoothing.
+*/
+func SavitzkyGolayElevation(window int) DirectOptions {
+	return elevationSmoothing(window, func(m int) []float64 {
+		coefficients := make([]float64, 2*m+1)
+		fm := float64(m)
+		norm := (2*fm + 1) * (2*fm + 3) * (2*fm - 1)
+		for i := -m; i <= m; i++ {
+			coefficients[i+m] = (3*(3*fm*fm+3*fm-1) - 15*float64(i*i)) / norm
+		}
+		return coefficients
+	})
+}
+
+/*
+elevationSmoothing convolves all elevations of a segment with the coefficients
+that are returned for a given half window size m (cf. MovingAverageElevation).
+*/
+func elevationSmoothing(window int, coefficients func(m int) []float64) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if window < 1 || window%2 == 0 {
+				return nil, errors.New(fmt.Sprintf("elevation smoothing requires an odd, positive window size; got %v", window))
+			}
+			indices := []int{}
+			elevations := []float64{}
+			for i, point := range segment.Points {
+				if point.Elevation.NotNull() {
+					indices = append(indices, i)
+					elevations = append(elevations, point.Elevation.Value())
+				}
+			}
+			points := make([]gpx.GPXPoint, len(segment.Points))
+			copy(points, segment.Points)
+			cache := map[int][]float64{}
+			for j, index := range indices {
+				m := min(window/2, j, len(elevations)-1-j)
+				if _, found := cache[m]; !found {
+					cache[m] = coefficients(m)
+				}
+				smoothed := 0.0
+				for k, c := range cache[m] {
+					smoothed += c * elevations[j-m+k]
+				}
+				points[index].Elevation.SetValue(smoothed)
+			}
+			segment.Points = points
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
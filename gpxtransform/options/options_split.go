@@ -1,6 +1,9 @@
 package options
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/tkrajina/gpxgo/gpx"
@@ -8,9 +11,22 @@ import (
 )
 
 /*
-SplitOptions hold a function that returns true iff a segment should be split at an indicated point
+SplitOptions hold a function that returns true iff a segment should be split at an indicated point.
+Options that need to look at the whole segment, e.g., at the distance travelled since its start,
+provide DoSegment instead of Do.
 */
-type SplitOptions TransformOptions
+type SplitOptions struct {
+	/*
+		Do must return true whenever the segment should be split between the point at the provided index and the next one.
+	*/
+	Do func(segment gpx.GPXTrackSegment, index int) (bool, error)
+	/*
+		DoSegment decides for all points of a segment at once and is called once per segment.
+		It must return one value per point, which is true whenever the segment should be split between that point and the next one.
+		If DoSegment is set, Do is ignored.
+	*/
+	DoSegment func(segment gpx.GPXTrackSegment) ([]bool, error)
+}
 
 /*
 A time-based splitter that splits whenever two consecutive points have a time
@@ -18,7 +34,7 @@ difference larger than maxStep.
 */
 func TimeSplit(maxStep time.Duration) SplitOptions {
 	return SplitOptions{
-		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+		Do: func(segment gpx.GPXTrackSegment, index int) (bool, error) {
 			duration := segment.Points[index+1].Timestamp.Sub(segment.Points[index].Timestamp)
 			return maxStep <= duration, nil
 		},
@@ -31,7 +47,7 @@ distance larger than maxDist.
 */
 func DistanceSplit(maxDist unit.Length) SplitOptions {
 	return SplitOptions{
-		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+		Do: func(segment gpx.GPXTrackSegment, index int) (bool, error) {
 			dist := gpx.Length3D([]gpx.Point{segment.Points[index].Point, segment.Points[index+1].Point})
 			return dist > float64(maxDist), nil
 		},
@@ -44,7 +60,7 @@ Different to RemoveStops, this function can be configured to not affect shorter
 */
 func PauseSplit(radius unit.Length, minDuration time.Duration) SplitOptions {
 	return SplitOptions{
-		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+		Do: func(segment gpx.GPXTrackSegment, index int) (bool, error) {
 			point := segment.Points[index]
 			for i := index - 1; i >= 0; i-- {
 				prevPoint := segment.Points[i]
@@ -89,3 +105,148 @@ func PauseSplit(radius unit.Length, minDuration time.Duration) SplitOptions {
 		},
 	}
 }
+
+/*
+AreaSplit splits whenever the track enters or leaves the provided area, i.e.,
+whenever exactly one of two consecutive points lies inside the area.
+*/
+func AreaSplit(area Area) SplitOptions {
+	return SplitOptions{
+		Do: func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			return area.Contains(segment.Points[index].Point) != area.Contains(segment.Points[index+1].Point), nil
+		},
+	}
+}
+
+/*
+CalendarPeriod is a period of the calendar, e.g., a day.
+*/
+type CalendarPeriod int
+
+const (
+	Day CalendarPeriod = iota
+	Week
+	Month
+)
+
+/*
+CalendarSplit splits whenever two consecutive points were recorded in different
+calendar periods, e.g., on different days, in the provided location. Weeks
+start on Monday (ISO 8601). Points without timestamps never cause a split.
+*/
+func CalendarSplit(location *time.Location, period CalendarPeriod) SplitOptions {
+	calendarPeriod := func(t time.Time) [2]int {
+		t = t.In(location)
+		switch period {
+		case Week:
+			year, week := t.ISOWeek()
+			return [2]int{year, week}
+		case Month:
+			return [2]int{t.Year(), int(t.Month())}
+		default:
+			return [2]int{t.Year(), t.YearDay()}
+		}
+	}
+	return SplitOptions{
+		Do: func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			a, b := segment.Points[index].Timestamp, segment.Points[index+1].Timestamp
+			if a.IsZero() || b.IsZero() {
+				return false, nil
+			}
+			return calendarPeriod(a) != calendarPeriod(b), nil
+		},
+	}
+}
+
+/*
+stoppedSpeed is the speed (in km/h) up to which points are considered to be
+stopped. It equals the threshold that gpxgo uses for its moving data.
+*/
+const stoppedSpeed = 1.0
+
+/*
+CumulativeDistanceSplit splits whenever the distance travelled since the start
+of the segment passes a multiple of every, e.g., to create laps of 1 km.
+*/
+func CumulativeDistanceSplit(every unit.Length) SplitOptions {
+	return cumulativeSplit(float64(every), func(a, b gpx.GPXPoint) float64 {
+		return a.Distance2D(&b)
+	})
+}
+
+/*
+CumulativeDurationSplit splits whenever the time travelled since the start of
+the segment passes a multiple of every. If movingOnly is true, only the time
+in which the speed exceeded 1 km/h is counted. Time between points without
+timestamps is not counted.
+*/
+func CumulativeDurationSplit(every time.Duration, movingOnly bool) SplitOptions {
+	return cumulativeSplit(float64(every), func(a, b gpx.GPXPoint) float64 {
+		if a.Timestamp.IsZero() || b.Timestamp.IsZero() {
+			return 0
+		}
+		if movingOnly && a.SpeedBetween(&b, false)*3.6 <= stoppedSpeed {
+			return 0
+		}
+		return float64(b.Timestamp.Sub(a.Timestamp))
+	})
+}
+
+/*
+cumulativeSplit splits whenever the cumulative sum of step since the start of
+the segment passes a multiple of every.
+*/
+func cumulativeSplit(every float64, step func(a, b gpx.GPXPoint) float64) SplitOptions {
+	return SplitOptions{
+		DoSegment: func(segment gpx.GPXTrackSegment) ([]bool, error) {
+			if every <= 0 {
+				return nil, errors.New(fmt.Sprintf("cumulative split requires a positive interval; got %v", every))
+			}
+			splits := make([]bool, len(segment.Points))
+			sum := 0.0
+			for i := 0; i < len(segment.Points)-1; i++ {
+				next := sum + step(segment.Points[i], segment.Points[i+1])
+				splits[i] = math.Floor(sum/every) < math.Floor(next/every)
+				sum = next
+			}
+			return splits, nil
+		},
+	}
+}
+
+/*
+ProximitySplit splits whenever the track passes within radius of any of the
+provided locations, e.g., bus or train stops. A pass consists of all
+consecutive points within radius of a location; the segment is split after the
+point of the pass that lies closest to the location. Passes that include the
+first or the last point of a segment do not cause a split, as the segment
+already starts or ends at that location.
+*/
+func ProximitySplit(locations []gpx.Point, radius unit.Length) SplitOptions {
+	return SplitOptions{
+		DoSegment: func(segment gpx.GPXTrackSegment) ([]bool, error) {
+			splits := make([]bool, len(segment.Points))
+			for _, location := range locations {
+				inPass, passAtStart := false, false
+				closest, closestDistance := 0, 0.0
+				for i, point := range segment.Points {
+					distance := point.Distance2D(&location)
+					if distance > float64(radius) {
+						if inPass && !passAtStart {
+							splits[closest] = true
+						}
+						inPass = false
+						continue
+					}
+					if !inPass {
+						inPass, passAtStart = true, i == 0
+						closest, closestDistance = i, distance
+					} else if distance < closestDistance {
+						closest, closestDistance = i, distance
+					}
+				}
+			}
+			return splits, nil
+		},
+	}
+}
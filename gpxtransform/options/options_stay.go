@@ -0,0 +1,126 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+Stay is a place where a segment stayed for some time, i.e., a cluster of
+consecutive points.
+*/
+type Stay struct {
+	// Start and End are the indices of the stay's first and last point
+	Start, End int
+	// Location is the centroid of the stay's points
+	Location  gpx.Point
+	Arrival   time.Time
+	Departure time.Time
+}
+
+/*
+Duration returns the time between arrival and departure.
+*/
+func (s Stay) Duration() time.Duration {
+	return s.Departure.Sub(s.Arrival)
+}
+
+/*
+Waypoint returns a waypoint at the location of the stay that holds the provided
+name and the arrival time as timestamp. Its description lists arrival,
+departure, and duration.
+*/
+func (s Stay) Waypoint(name string) gpx.GPXPoint {
+	return gpx.GPXPoint{
+		Point:       s.Location,
+		Timestamp:   s.Arrival,
+		Name:        name,
+		Description: fmt.Sprintf("Arrival: %v; Departure: %v; Duration: %v", s.Arrival.Format(time.RFC3339), s.Departure.Format(time.RFC3339), s.Duration()),
+	}
+}
+
+/*
+FindStays returns all stays of a segment, i.e., all runs of consecutive points
+that lie within radius of the run's first point and that span at least
+minDuration. Unlike PauseSplit, which only detects where a pause ends, the
+whole run of points is returned. Segments without timestamps have no stays.
+*/
+func FindStays(segment gpx.GPXTrackSegment, radius unit.Length, minDuration time.Duration) []Stay {
+	stays := []Stay{}
+	points := segment.Points
+	if !hasTimestamps(segment) {
+		return stays
+	}
+	for i := 0; i < len(points); {
+		j := i + 1
+		for j < len(points) && points[i].Distance2D(&points[j]) <= float64(radius) {
+			j++
+		}
+		if points[j-1].Timestamp.Sub(points[i].Timestamp) < minDuration {
+			i++
+			continue
+		}
+		stay := Stay{Start: i, End: j - 1, Arrival: points[i].Timestamp, Departure: points[j-1].Timestamp}
+		elevations := 0
+		for _, point := range points[i:j] {
+			stay.Location.Latitude += point.Latitude
+			stay.Location.Longitude += point.Longitude
+			if point.Elevation.NotNull() {
+				stay.Location.Elevation.SetValue(stay.Location.Elevation.Value() + point.Elevation.Value())
+				elevations++
+			}
+		}
+		stay.Location.Latitude /= float64(j - i)
+		stay.Location.Longitude /= float64(j - i)
+		if elevations > 0 {
+			stay.Location.Elevation.SetValue(stay.Location.Elevation.Value() / float64(elevations))
+		}
+		stays = append(stays, stay)
+		i = j
+	}
+	return stays
+}
+
+/*
+RemoveStays removes the points of all stays of a segment (cf. FindStays) and
+splits the segment wherever a stay was removed. Segments that consist of a
+single stay are removed entirely. See ExtractStays in the gpxtransform package
+for keeping stays as waypoints.
+*/
+func RemoveStays(radius unit.Length, minDuration time.Duration) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			return WithoutStays(segment, FindStays(segment, radius, minDuration)), nil
+		},
+	}
+}
+
+/*
+WithoutStays returns the parts of a segment before, between, and after the
+provided stays, which must be ordered as returned by FindStays. Parts without
+points are omitted.
+*/
+func WithoutStays(segment gpx.GPXTrackSegment, stays []Stay) []gpx.GPXTrackSegment {
+	if len(stays) == 0 {
+		return []gpx.GPXTrackSegment{segment}
+	}
+	segments := []gpx.GPXTrackSegment{}
+	start := 0
+	for _, stay := range stays {
+		if stay.Start > start {
+			part := segment
+			part.Points = segment.Points[start:stay.Start:stay.Start]
+			segments = append(segments, part)
+		}
+		start = stay.End + 1
+	}
+	if start < len(segment.Points) {
+		part := segment
+		part.Points = segment.Points[start:]
+		segments = append(segments, part)
+	}
+	return segments
+}
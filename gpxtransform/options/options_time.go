@@ -0,0 +1,135 @@
+package options
+
+import (
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+TimeCondition returns true iff the provided timestamp satisfies the condition.
+*/
+type TimeCondition func(t time.Time) bool
+
+/*
+TimeRange is satisfied by timestamps within [from, to). A zero from or to
+leaves the corresponding side of the range open.
+*/
+func TimeRange(from, to time.Time) TimeCondition {
+	return func(t time.Time) bool {
+		if !from.IsZero() && t.Before(from) {
+			return false
+		}
+		if !to.IsZero() && !t.Before(to) {
+			return false
+		}
+		return true
+	}
+}
+
+/*
+Weekdays is satisfied by timestamps that fall on one of the provided weekdays
+in the provided location.
+*/
+func Weekdays(location *time.Location, weekdays ...time.Weekday) TimeCondition {
+	return func(t time.Time) bool {
+		weekday := t.In(location).Weekday()
+		for _, w := range weekdays {
+			if w == weekday {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+/*
+TimeOfDay is satisfied by timestamps whose local time of day (in the provided
+location) lies within [from, to), both given as offsets from midnight. If from
+is later than to, the range wraps around midnight.
+*/
+func TimeOfDay(location *time.Location, from, to time.Duration) TimeCondition {
+	return func(t time.Time) bool {
+		local := t.In(location)
+		offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+			time.Duration(local.Second())*time.Second + time.Duration(local.Nanosecond())
+		if from <= to {
+			return from <= offset && offset < to
+		}
+		return from <= offset || offset < to
+	}
+}
+
+/*
+AllTime is satisfied iff all provided conditions are satisfied.
+*/
+func AllTime(conditions ...TimeCondition) TimeCondition {
+	return func(t time.Time) bool {
+		for _, condition := range conditions {
+			if !condition(t) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+/*
+KeepTime cuts all points whose timestamp does not satisfy the provided condition.
+Points without timestamp are kept.
+*/
+func KeepTime(condition TimeCondition) FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			timestamp := segment.Points[index].Timestamp
+			return timestamp.IsZero() || condition(timestamp), nil
+		},
+	}
+}
+
+/*
+DropTime cuts all points whose timestamp satisfies the provided condition.
+Points without timestamp are kept.
+*/
+func DropTime(condition TimeCondition) FilterOptions {
+	return FilterOptions{
+		func(segment gpx.GPXTrackSegment, index int) (bool, error) {
+			timestamp := segment.Points[index].Timestamp
+			return timestamp.IsZero() || !condition(timestamp), nil
+		},
+	}
+}
+
+/*
+KeepSegmentTime removes segments whose start time does not satisfy the provided condition.
+*/
+func KeepSegmentTime(condition TimeCondition) DirectOptions {
+	remove := func(startTime time.Time) bool {
+		return !condition(startTime)
+	}
+	return segmentStartFilter(remove)
+}
+
+/*
+DropSegmentTime removes segments whose start time satisfies the provided condition.
+*/
+func DropSegmentTime(condition TimeCondition) DirectOptions {
+	return segmentStartFilter(condition)
+}
+
+/*
+segmentStartFilter removes segments, if the remove function returns true when passing their corresponding start time.
+*/
+func segmentStartFilter(remove func(startTime time.Time) bool) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if len(segment.Points) == 0 || segment.Points[0].Timestamp.IsZero() {
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			if remove(segment.Points[0].Timestamp) {
+				return []gpx.GPXTrackSegment{}, nil
+			}
+			return []gpx.GPXTrackSegment{segment}, nil
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package options
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+minTurnaroundOverlap is the share of return points that must lie close to the
+outbound path for a segment to be considered an out-and-back track.
+*/
+const minTurnaroundOverlap = 0.5
+
+/*
+turnaroundSamples is the maximal number of return points that are compared
+with the outbound path, so that long tracks are checked in linear time.
+*/
+const turnaroundSamples = 200
+
+/*
+TurnaroundSplit divides an out-and-back track at its turnaround point, i.e.,
+at the point farthest from the start, into an outbound and a return leg. Both
+legs share the turnaround point. A segment is only divided if at least half of
+the points of the return leg lie within tolerance of the outbound leg, so that
+loops and one-way tracks are not modified. For long return legs, an evenly
+spaced sample of their points is checked.
+*/
+func TurnaroundSplit(tolerance unit.Length) DirectOptions {
+	return DirectOptions{
+		func(segment gpx.GPXTrackSegment) ([]gpx.GPXTrackSegment, error) {
+			if len(segment.Points) < 3 {
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			xs, ys := project(segment.Points)
+			turnaround, farthest := 0, 0.0
+			for i := range segment.Points {
+				if distance := math.Hypot(xs[i], ys[i]); distance > farthest {
+					turnaround, farthest = i, distance
+				}
+			}
+			if turnaround == 0 || turnaround == len(segment.Points)-1 {
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			returnPoints := len(segment.Points) - 1 - turnaround
+			step := max(1, returnPoints/turnaroundSamples)
+			sampled, overlapping := 0, 0
+			for i := turnaround + 1; i < len(segment.Points); i += step {
+				sampled++
+				for j := 0; j < turnaround; j++ {
+					if segmentDistance(xs[i], ys[i], xs[j], ys[j], xs[j+1], ys[j+1]) <= float64(tolerance) {
+						overlapping++
+						break
+					}
+				}
+			}
+			if float64(overlapping) < minTurnaroundOverlap*float64(sampled) {
+				slog.Info("TurnaroundSplit: segment is not an out-and-back track and is not divided")
+				return []gpx.GPXTrackSegment{segment}, nil
+			}
+			return cutAt(segment, []int{turnaround}), nil
+		},
+	}
+}
+
+/*
+segmentDistance returns the distance of the projected point (x, y) to the line
+segment from (x1, y1) to (x2, y2).
+*/
+func segmentDistance(x, y, x1, y1, x2, y2 float64) float64 {
+	dx, dy := x2-x1, y2-y1
+	fraction := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		fraction = math.Max(0, math.Min(1, ((x-x1)*dx+(y-y1)*dy)/length))
+	}
+	return math.Hypot(x-(x1+fraction*dx), y-(y1+fraction*dy))
+}
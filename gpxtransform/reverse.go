@@ -0,0 +1,113 @@
+package gpxtransform
+
+import (
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+ReverseSegment creates a "GPXSegmentTransform"er that reverses the order of
+all points of a segment. If mirrorTime is true, timestamps are mirrored within
+the segment's time span, so that time still increases.
+*/
+func ReverseSegment(mirrorTime bool) config.GPXSegmentTransform {
+	return func(trackSegment gpx.GPXTrackSegment) (segments []gpx.GPXTrackSegment, err error) {
+		points := make([]gpx.GPXPoint, len(trackSegment.Points))
+		for i, point := range trackSegment.Points {
+			points[len(points)-1-i] = point
+		}
+		if mirrorTime {
+			first, last := timeSpan(points)
+			mirror := options.MirrorTime(first, last)
+			for i := range points {
+				if !points[i].Timestamp.IsZero() {
+					points[i].Timestamp = mirror(points[i].Timestamp)
+				}
+			}
+		}
+		trackSegment.Points = points
+		return []gpx.GPXTrackSegment{trackSegment}, nil
+	}
+}
+
+/*
+ReverseTrack creates a "GPXTrackTransform"er that reverses the order of all
+segments of a track. Points of segments are not reversed (cf. ReverseSegment).
+*/
+func ReverseTrack() config.GPXTrackTransform {
+	return func(gpxTrack gpx.GPXTrack) (tracks []gpx.GPXTrack, err error) {
+		segments := make([]gpx.GPXTrackSegment, len(gpxTrack.Segments))
+		for i, segment := range gpxTrack.Segments {
+			segments[len(segments)-1-i] = segment
+		}
+		gpxTrack.Segments = segments
+		return []gpx.GPXTrack{gpxTrack}, nil
+	}
+}
+
+/*
+ReverseFile creates a "GPXFileTransform"er that reverses the order of all
+tracks and routes of a file, as well as the points of every route. Segments
+and track points are not reversed (cf. ReverseTrack and ReverseSegment). If
+mirrorTime is true, the timestamps of all points, including those of
+waypoints, are mirrored within the file's time span. The time of the file's
+metadata is kept. Combined with ReverseTrack and ReverseSegment, time still
+increases across all segments, tracks, and routes.
+*/
+func ReverseFile(mirrorTime bool) config.GPXFileTransform {
+	return func(gpxFile gpx.GPX) (files []gpx.GPX, err error) {
+		tracks := make([]gpx.GPXTrack, len(gpxFile.Tracks))
+		for i, track := range gpxFile.Tracks {
+			tracks[len(tracks)-1-i] = track
+		}
+		gpxFile.Tracks = tracks
+		routes := make([]gpx.GPXRoute, len(gpxFile.Routes))
+		for i, route := range gpxFile.Routes {
+			points := make([]gpx.GPXPoint, len(route.Points))
+			for j, point := range route.Points {
+				points[len(points)-1-j] = point
+			}
+			route.Points = points
+			routes[len(routes)-1-i] = route
+		}
+		gpxFile.Routes = routes
+		if mirrorTime {
+			points := []gpx.GPXPoint{}
+			options.MapPoints(gpxFile, func(point gpx.GPXPoint) gpx.GPXPoint {
+				points = append(points, point)
+				return point
+			})
+			first, last := timeSpan(points)
+			mirror := options.MirrorTime(first, last)
+			gpxFile = options.MapPoints(gpxFile, func(point gpx.GPXPoint) gpx.GPXPoint {
+				if !point.Timestamp.IsZero() {
+					point.Timestamp = mirror(point.Timestamp)
+				}
+				return point
+			})
+		}
+		return []gpx.GPX{gpxFile}, nil
+	}
+}
+
+/*
+timeSpan returns the earliest and the latest timestamp of the provided points.
+Missing timestamps are ignored.
+*/
+func timeSpan(points []gpx.GPXPoint) (first, last time.Time) {
+	for _, point := range points {
+		if point.Timestamp.IsZero() {
+			continue
+		}
+		if first.IsZero() || point.Timestamp.Before(first) {
+			first = point.Timestamp
+		}
+		if last.IsZero() || point.Timestamp.After(last) {
+			last = point.Timestamp
+		}
+	}
+	return
+}
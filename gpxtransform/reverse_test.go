@@ -0,0 +1,96 @@
+package gpxtransform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+func TestReverse(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	original := gpxFile.Tracks[0].Segments
+	created := time.Date(2024, 5, 12, 9, 0, 0, 0, time.UTC)
+	gpxFile.Time = &created
+	tc := config.NewTransformConfig(
+		config.WithSegmentTransform(ReverseSegment(false)),
+		config.WithTrackTransform(ReverseTrack()),
+		config.WithFileTransform(ReverseFile(true)),
+	)
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	reversed := gpxFiles[0].Tracks[0].Segments
+	assert.Equal(t, len(original), len(reversed))
+	lastSegment := original[len(original)-1].Points
+	assert.Equal(t, lastSegment[len(lastSegment)-1].Latitude, reversed[0].Points[0].Latitude)
+	assert.Equal(t, original[0].Points[0].Latitude, reversed[len(reversed)-1].Points[len(reversed[len(reversed)-1].Points)-1].Latitude)
+	// time still increases across all segments and covers the original span
+	assert.True(t, original[0].Points[0].Timestamp.Equal(reversed[0].Points[0].Timestamp))
+	previous := reversed[0].Points[0].Timestamp
+	for _, segment := range reversed {
+		for _, point := range segment.Points {
+			assert.False(t, point.Timestamp.Before(previous))
+			previous = point.Timestamp
+		}
+	}
+	assert.True(t, lastSegment[len(lastSegment)-1].Timestamp.Equal(previous))
+	// the time of the metadata is not mirrored
+	assert.True(t, created.Equal(*gpxFiles[0].Time))
+	// the original file is not modified
+	assert.True(t, gpxFile.Tracks[0].Segments[0].Points[0].Timestamp.Before(gpxFile.Tracks[0].Segments[0].Points[1].Timestamp))
+
+	tc = config.NewTransformConfig(config.WithSegmentTransform(ReverseSegment(true)))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	segment := gpxFiles[0].Tracks[0].Segments[0].Points
+	assert.Equal(t, original[0].Points[len(original[0].Points)-1].Latitude, segment[0].Latitude)
+	assert.True(t, original[0].Points[0].Timestamp.Equal(segment[0].Timestamp))
+	assert.True(t, original[0].Points[1].Timestamp.Equal(segment[1].Timestamp))
+}
+
+const gpxDataRoute = `<?xml version="1.0"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+<wpt lat="50.0010" lon="8.0000"><time>2024-05-06T08:01:00Z</time><name>Bakery</name></wpt>
+<rte>
+  <name>First</name>
+  <rtept lat="50.0000" lon="8.0000"><time>2024-05-06T08:00:00Z</time></rtept>
+  <rtept lat="50.0010" lon="8.0000"><time>2024-05-06T08:01:00Z</time></rtept>
+  <rtept lat="50.0020" lon="8.0000"><time>2024-05-06T08:02:00Z</time></rtept>
+</rte>
+<rte>
+  <name>Second</name>
+  <rtept lat="50.0020" lon="8.0000"><time>2024-05-06T08:05:00Z</time></rtept>
+  <rtept lat="50.0030" lon="8.0000"><time>2024-05-06T08:08:00Z</time></rtept>
+</rte>
+</gpx>
+`
+
+func TestReverseRoutes(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataRoute))
+	assert.NoError(t, err)
+	files, err := ReverseFile(true)(*gpxFile)
+	assert.NoError(t, err)
+	routes := files[0].Routes
+	assert.Equal(t, 2, len(routes))
+	assert.Equal(t, "Second", routes[0].Name)
+	assert.Equal(t, 50.0030, routes[0].Points[0].Latitude)
+	assert.Equal(t, 50.0000, routes[1].Points[2].Latitude)
+	// time still increases across all routes and covers the original span
+	previous := routes[0].Points[0].Timestamp
+	assert.True(t, gpxFile.Routes[0].Points[0].Timestamp.Equal(previous))
+	for _, route := range routes {
+		for _, point := range route.Points {
+			assert.False(t, point.Timestamp.Before(previous))
+			previous = point.Timestamp
+		}
+	}
+	assert.True(t, gpxFile.Routes[1].Points[1].Timestamp.Equal(previous))
+	// the waypoint is passed at the mirrored time
+	assert.True(t, routes[1].Points[1].Timestamp.Equal(files[0].Waypoints[0].Timestamp))
+	// the original file is not modified
+	assert.Equal(t, "First", gpxFile.Routes[0].Name)
+	assert.Equal(t, 50.0000, gpxFile.Routes[0].Points[0].Latitude)
+}
@@ -0,0 +1,23 @@
+package gpxtransform
+
+import (
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+Sanitize creates a "GPXFileTransform"er that applies the provided sanitize
+options, one after another, on a file.
+*/
+func Sanitize(sanitizeOptions ...options.SanitizeOptions) config.GPXFileTransform {
+	return func(gpxFile gpx.GPX) (files []gpx.GPX, err error) {
+		for _, sanitizeOption := range sanitizeOptions {
+			gpxFile, err = sanitizeOption.Do(gpxFile)
+			if err != nil {
+				return
+			}
+		}
+		return []gpx.GPX{gpxFile}, nil
+	}
+}
@@ -0,0 +1,69 @@
+package gpxtransform
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+const gpxDataPersonal = `<?xml version="1.0"?>
+<gpx creator="Garmin Edge 530" version="1.1" xmlns="http://www.topografix.com/GPX/1/1" xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+<metadata>
+  <name>Commute</name>
+  <author><name>Jane Doe</name><email id="jane" domain="example.com"/></author>
+  <time>2024-05-06T07:50:00Z</time>
+</metadata>
+<trk>
+  <name>Commute</name>
+  <src>Garmin Edge 530</src>
+  <trkseg>
+    <trkpt lat="50.1234567" lon="8.7654321">
+      <time>2024-05-06T08:00:00Z</time>
+      <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+    </trkpt>
+    <trkpt lat="50.1244567" lon="8.7664321">
+      <time>2024-05-06T08:01:00Z</time>
+      <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>125</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+    </trkpt>
+  </trkseg>
+</trk>
+</gpx>
+`
+
+func TestSanitize(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataPersonal))
+	assert.NoError(t, err)
+	epoch := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	tc := config.NewTransformConfig(config.WithFileTransform(Sanitize(
+		options.StripMetadata(),
+		options.StripExtensions(),
+		options.RoundCoordinates(4),
+		options.RelativeTime(epoch),
+	)))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(gpxFiles))
+	sanitized := gpxFiles[0]
+	assert.Equal(t, "Commute", sanitized.Name)
+	assert.Equal(t, "", sanitized.AuthorName)
+	assert.Nil(t, sanitized.Time)
+	assert.Equal(t, "", sanitized.Tracks[0].Source)
+	points := sanitized.Tracks[0].Segments[0].Points
+	assert.Equal(t, 50.1235, points[0].Latitude)
+	assert.Equal(t, 8.7664, points[1].Longitude)
+	assert.True(t, epoch.Equal(points[0].Timestamp))
+	assert.True(t, epoch.Add(time.Minute).Equal(points[1].Timestamp))
+	xmlBytes, err := sanitized.ToXml(gpx.ToXmlParams{Version: "1.1"})
+	assert.NoError(t, err)
+	for _, personal := range []string{"Garmin", "Jane", "hr", "2024"} {
+		assert.False(t, strings.Contains(string(xmlBytes), personal), personal)
+	}
+	// the original file is not modified
+	assert.Equal(t, "Garmin Edge 530", gpxFile.Tracks[0].Source)
+	assert.Equal(t, 50.1234567, gpxFile.Tracks[0].Segments[0].Points[0].Latitude)
+}
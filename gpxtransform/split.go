@@ -1,6 +1,9 @@
 package gpxtransform
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/abzicht/gpsplit/gpxtransform/config"
 	"github.com/abzicht/gpsplit/gpxtransform/options"
 	"github.com/tkrajina/gpxgo/gpx"
@@ -63,16 +66,35 @@ type splitIndices []int
 
 /*
 Returns the indices at that a trackSegment should be split based on the provided options.
+Options that decide for all points at once (cf. options.SplitOptions.DoSegment) are evaluated once.
 */
 func findSplitIndices(trackSegment gpx.GPXTrackSegment, splitOptions ...options.SplitOptions) (sI splitIndices, err error) {
 	sI = splitIndices{}
 	points := trackSegment.Points
+	segmentSplits := make([][]bool, len(splitOptions))
+	for optionIndex, splitOption := range splitOptions {
+		if splitOption.DoSegment == nil {
+			continue
+		}
+		segmentSplits[optionIndex], err = splitOption.DoSegment(trackSegment)
+		if err != nil {
+			return
+		}
+		if len(segmentSplits[optionIndex]) != len(points) {
+			err = errors.New(fmt.Sprintf("split options returned %v values for %v points", len(segmentSplits[optionIndex]), len(points)))
+			return
+		}
+	}
 	for index := 0; index < len(points)-1; index++ {
 		var doSplit bool = false
-		for _, splitOption := range splitOptions {
-			doSplit, err = splitOption.Do(trackSegment, index)
-			if err != nil {
-				return
+		for optionIndex, splitOption := range splitOptions {
+			if splitOption.DoSegment != nil {
+				doSplit = segmentSplits[optionIndex][index]
+			} else {
+				doSplit, err = splitOption.Do(trackSegment, index)
+				if err != nil {
+					return
+				}
 			}
 			if doSplit {
 				break
@@ -1,8 +1,11 @@
 package gpxtransform
 
 import (
+	"math"
 	"testing"
+	"time"
 
+	"github.com/abzicht/gogenericfunc/fun"
 	"github.com/abzicht/gpsplit/gpxtransform/config"
 	"github.com/abzicht/gpsplit/gpxtransform/options"
 	"github.com/stretchr/testify/assert"
@@ -106,3 +109,238 @@ func TestSplit(t *testing.T) {
 	assert.Equal(t, 4, len(gpxFiles[0].Tracks[0].Segments))
 	assert.Equal(t, 1, len(gpxFiles[0].Tracks[0].Segments[0].Points))
 }
+
+func TestCalendarSplit(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	tc := config.NewTransformConfig(config.WithSegmentTransform(Split(options.CalendarSplit(time.UTC, options.Day))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments))
+
+	// midnight in Anchorage (UTC-8 in May) equals 08:00 UTC
+	anchorage, err := time.LoadLocation("America/Anchorage")
+	assert.NoError(t, err)
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Split(options.CalendarSplit(anchorage, options.Day))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(gpxFiles[0].Tracks[0].Segments))
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments[0].Points))
+	assert.Equal(t, 4, len(gpxFiles[0].Tracks[0].Segments[1].Points))
+
+	// Sunday and Monday belong to different weeks, but to the same month
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Split(options.CalendarSplit(anchorage, options.Week))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(gpxFiles[0].Tracks[0].Segments))
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Split(options.CalendarSplit(anchorage, options.Month))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(gpxFiles[0].Tracks[0].Segments))
+}
+
+func TestCumulativeSplit(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	// points are about 111 meters apart
+	tc := config.NewTransformConfig(config.WithSegmentTransform(Split(options.CumulativeDistanceSplit(250 * unit.Metre))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	segments := gpxFiles[0].Tracks[0].Segments
+	assert.Equal(t, 4, len(segments))
+	assert.Equal(t, 3, len(segments[0].Points))
+	assert.Equal(t, 2, len(segments[1].Points))
+	assert.Equal(t, 1, len(segments[2].Points))
+	assert.Equal(t, 2, len(segments[3].Points))
+
+	tc = config.NewTransformConfig(config.WithSegmentTransform(Split(options.CumulativeDurationSplit(2*time.Minute, false))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	segments = gpxFiles[0].Tracks[0].Segments
+	assert.Equal(t, 4, len(segments))
+	assert.Equal(t, 2, len(segments[0].Points))
+	assert.Equal(t, 2, len(segments[1].Points))
+	assert.Equal(t, 2, len(segments[2].Points))
+
+	// options hold no state, so they can be reused for points changed in place
+	split := Split(options.CumulativeDistanceSplit(250 * unit.Metre))
+	segment := gpx.GPXTrackSegment{Points: append([]gpx.GPXPoint{}, gpxFile.Tracks[0].Segments[0].Points...)}
+	segments, err = split(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(segments))
+	for i := range segment.Points {
+		segment.Points[i].Latitude = 50 + 0.0001*float64(i)
+	}
+	segments, err = split(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(segments))
+
+	// whole-segment decisions must cover every point
+	invalid := options.SplitOptions{DoSegment: func(segment gpx.GPXTrackSegment) ([]bool, error) {
+		return []bool{true}, nil
+	}}
+	_, err = Split(invalid)(segment)
+	assert.Error(t, err)
+}
+
+func TestCumulativeDurationSplitMovingTime(t *testing.T) {
+	// a stop of 5 minutes between the second and the third point
+	segment := gpx.GPXTrackSegment{Points: []gpx.GPXPoint{
+		fixturePoint(50.000, 0),
+		fixturePoint(50.001, time.Minute),
+		fixturePoint(50.001, 6*time.Minute),
+		fixturePoint(50.002, 7*time.Minute),
+	}}
+	segments, err := Split(options.CumulativeDurationSplit(2*time.Minute, false))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(segments))
+	assert.Equal(t, 2, len(segments[0].Points))
+	segments, err = Split(options.CumulativeDurationSplit(2*time.Minute, true))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(segments))
+	assert.Equal(t, 3, len(segments[0].Points))
+}
+
+func TestProximitySplit(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	// the first segment passes the second location closest at its fourth point,
+	// the second segment starts at the third location
+	locations := []gpx.Point{
+		{Latitude: 50.0000, Longitude: 8.0000},
+		{Latitude: 50.0029, Longitude: 8.0001},
+		{Latitude: 50.0050, Longitude: 8.0000},
+	}
+	tc := config.NewTransformConfig(config.WithSegmentTransform(Split(options.ProximitySplit(locations, 150*unit.Metre))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	segments := gpxFiles[0].Tracks[0].Segments
+	assert.Equal(t, 3, len(segments))
+	assert.Equal(t, 4, len(segments[0].Points))
+	assert.Equal(t, 2, len(segments[1].Points))
+	assert.Equal(t, 2, len(segments[2].Points))
+
+	// options hold no state, so they can be reused for points changed in place
+	split := Split(options.ProximitySplit(locations, 150*unit.Metre))
+	segment := gpx.GPXTrackSegment{Points: append([]gpx.GPXPoint{}, gpxFile.Tracks[0].Segments[0].Points...)}
+	segments, err = split(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(segments))
+	for i := range segment.Points {
+		segment.Points[i].Longitude = 9
+	}
+	segments, err = split(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(segments))
+}
+
+func TestLapSplit(t *testing.T) {
+	segments, err := Split(options.LapSplit(fun.NewNone[gpx.Point](), 20*unit.Metre))(circuit(1, 1, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(segments))
+	assert.Equal(t, 37, len(segments[0].Points))
+	assert.Equal(t, 36, len(segments[1].Points))
+	assert.Equal(t, 36, len(segments[2].Points))
+
+	// the third lap runs in the opposite direction
+	segments, err = Split(options.LapSplit(fun.NewNone[gpx.Point](), 20*unit.Metre))(circuit(1, 1, -1))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(segments))
+	assert.Equal(t, 37, len(segments[0].Points))
+
+	// a reference on the western side
+	west := gpx.Point{Latitude: 50, Longitude: 8 - 100/(111195*math.Cos(50*math.Pi/180))}
+	segments, err = Split(options.LapSplit(fun.NewSome(west), 20*unit.Metre))(circuit(1, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(segments))
+	assert.Equal(t, 19, len(segments[0].Points))
+	assert.Equal(t, 36, len(segments[1].Points))
+}
+
+func TestLineSplit(t *testing.T) {
+	// a line across the circle between points 8 and 9 of every lap
+	a := gpx.Point{Latitude: 50 + 80/111195.0, Longitude: 8.00005}
+	b := gpx.Point{Latitude: 50 + 120/111195.0, Longitude: 8.00015}
+	segments, err := Split(options.LineSplit(a, b))(circuit(1, 1, -1))
+	assert.NoError(t, err)
+	// the clockwise lap crosses the line in the opposite direction
+	assert.Equal(t, 3, len(segments))
+	assert.Equal(t, 9, len(segments[0].Points))
+	assert.Equal(t, 36, len(segments[1].Points))
+
+	_, err = Split(options.LineSplit(a, a))(circuit(1))
+	assert.Error(t, err)
+}
+
+func TestTurnaroundSplit(t *testing.T) {
+	// out and back with the return leg 10 meters to the east
+	segment := gpx.GPXTrackSegment{}
+	for i := 0; i <= 5; i++ {
+		segment.Points = append(segment.Points, gpx.GPXPoint{Point: gpx.Point{Latitude: 50 + 0.001*float64(i), Longitude: 8}})
+	}
+	for i := 4; i >= 0; i-- {
+		segment.Points = append(segment.Points, gpx.GPXPoint{Point: gpx.Point{Latitude: 50 + 0.001*float64(i), Longitude: 8.00014}})
+	}
+	segments, err := Direct(options.TurnaroundSplit(30 * unit.Metre))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(segments))
+	assert.Equal(t, 6, len(segments[0].Points))
+	assert.Equal(t, 6, len(segments[1].Points))
+	assert.Equal(t, segments[0].Points[5], segments[1].Points[0])
+
+	segments, err = Direct(options.TurnaroundSplit(5 * unit.Metre))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(segments))
+
+	// loops are not divided
+	segments, err = Direct(options.TurnaroundSplit(30 * unit.Metre))(circuit(1))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(segments))
+
+	// a long out and back, e.g., a 1 Hz log, is checked on a sample of return points
+	segment = gpx.GPXTrackSegment{}
+	for i := 0; i <= 20000; i++ {
+		segment.Points = append(segment.Points, gpx.GPXPoint{Point: gpx.Point{Latitude: 50 + 0.00002*float64(i), Longitude: 8}})
+	}
+	for i := 19999; i >= 0; i-- {
+		segment.Points = append(segment.Points, gpx.GPXPoint{Point: gpx.Point{Latitude: 50 + 0.00002*float64(i), Longitude: 8.00014}})
+	}
+	segments, err = Direct(options.TurnaroundSplit(30 * unit.Metre))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(segments))
+	assert.Equal(t, 20001, len(segments[0].Points))
+}
+
+func TestModeSplit(t *testing.T) {
+	segment := journey(1.4, 5, 0, 20)
+	segments, err := Split(options.ModeSplit(2 * time.Minute))(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(segments))
+	for _, s := range segments {
+		assert.InDelta(t, 120, len(s.Points), 2)
+	}
+
+	tc := config.NewTransformConfig(
+		config.WithSegmentTransform(Split(options.ModeSplit(2*time.Minute))),
+		config.WithTrackTransform(ChainTrack(SplitTrackBySegment(), TagMode(2*time.Minute))),
+	)
+	gpxFiles, err := TransformFile(gpx.GPX{Tracks: []gpx.GPXTrack{{Segments: []gpx.GPXTrackSegment{segment}}}}, tc)
+	assert.NoError(t, err)
+	types := []string{}
+	for _, track := range gpxFiles[0].Tracks {
+		types = append(types, track.Type)
+	}
+	assert.Equal(t, []string{"walking", "cycling", "stationary", "motorized"}, types)
+
+	// options hold no state, so they can be reused for points changed in place
+	split := Split(options.ModeSplit(2 * time.Minute))
+	segments, err = split(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(segments))
+	for i := range segment.Points {
+		segment.Points[i].Latitude = 50 + float64(i)*5*1.4/111195
+	}
+	segments, err = split(segment)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(segments))
+}
@@ -0,0 +1,47 @@
+package gpxtransform
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+/*
+ExtractStays creates a "GPXFileTransform"er that adds a waypoint for every stay
+of every track segment (cf. options.FindStays), i.e., for every place where
+the segment stayed within radius for at least minDuration. Waypoints are
+named "Stay 1", "Stay 2", and so on in the order of tracks and segments and
+describe arrival, departure, and duration. If remove is true, the points of
+all stays are removed from the tracks and segments are split where stays were
+removed (cf. options.RemoveStays).
+*/
+func ExtractStays(radius unit.Length, minDuration time.Duration, remove bool) config.GPXFileTransform {
+	return func(gpxFile gpx.GPX) (files []gpx.GPX, err error) {
+		waypoints := slices.Clone(gpxFile.Waypoints)
+		tracks := make([]gpx.GPXTrack, len(gpxFile.Tracks))
+		for trackIndex, track := range gpxFile.Tracks {
+			segments := []gpx.GPXTrackSegment{}
+			for _, segment := range track.Segments {
+				stays := options.FindStays(segment, radius, minDuration)
+				for _, stay := range stays {
+					waypoints = append(waypoints, stay.Waypoint(fmt.Sprintf("Stay %v", len(waypoints)-len(gpxFile.Waypoints)+1)))
+				}
+				if remove {
+					segments = append(segments, options.WithoutStays(segment, stays)...)
+				} else {
+					segments = append(segments, segment)
+				}
+			}
+			track.Segments = segments
+			tracks[trackIndex] = track
+		}
+		gpxFile.Waypoints = waypoints
+		gpxFile.Tracks = tracks
+		return []gpx.GPX{gpxFile}, nil
+	}
+}
@@ -0,0 +1,72 @@
+package gpxtransform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/tkrajina/gpxgo/gpx"
+	"gonum.org/v1/gonum/unit"
+)
+
+func TestExtractStays(t *testing.T) {
+	segment := gpx.GPXTrackSegment{}
+	latitude := 50.0
+	offset := time.Duration(0)
+	addPoint := func(step float64, duration time.Duration) {
+		latitude += step
+		offset += duration
+		segment.Points = append(segment.Points, fixturePoint(latitude, offset))
+	}
+	// 4 points moving about 111m per minute, 6 points staying for 10 minutes, and 4 points moving again;
+	// the stay starts at the last moving point, which already lies within the radius
+	for i := 0; i < 4; i++ {
+		addPoint(0.001, time.Minute)
+	}
+	for i := 0; i < 6; i++ {
+		addPoint(0.00001, 2*time.Minute)
+	}
+	for i := 0; i < 4; i++ {
+		addPoint(0.001, time.Minute)
+	}
+	gpxFile := gpx.GPX{
+		Waypoints: []gpx.GPXPoint{{Name: "Home"}},
+		Tracks:    []gpx.GPXTrack{{Segments: []gpx.GPXTrackSegment{segment}}},
+	}
+
+	files, err := ExtractStays(50*unit.Metre, 5*time.Minute, false)(gpxFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, 2, len(files[0].Waypoints))
+	assert.Equal(t, 1, len(gpxFile.Waypoints))
+	stay := files[0].Waypoints[1]
+	assert.Equal(t, "Stay 1", stay.Name)
+	assert.Equal(t, segment.Points[3].Timestamp, stay.Timestamp)
+	assert.Equal(t, "Arrival: 2024-05-06T08:04:00Z; Departure: 2024-05-06T08:16:00Z; Duration: 12m0s", stay.Description)
+	assert.InDelta(t, 50.0040, stay.Latitude, 0.0001)
+	assert.Equal(t, 14, len(files[0].Tracks[0].Segments[0].Points))
+
+	files, err = ExtractStays(50*unit.Metre, 5*time.Minute, true)(gpxFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(files[0].Waypoints))
+	// the segment is split where the stay was removed
+	segments := files[0].Tracks[0].Segments
+	assert.Equal(t, 2, len(segments))
+	assert.Equal(t, 3, len(segments[0].Points))
+	assert.Equal(t, 4, len(segments[1].Points))
+	assert.Equal(t, segment.Points[2], segments[0].Points[2])
+	assert.Equal(t, segment.Points[10], segments[1].Points[0])
+
+	// stays shorter than the minimal duration are ignored
+	files, err = ExtractStays(50*unit.Metre, 15*time.Minute, true)(gpxFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(files[0].Waypoints))
+	assert.Equal(t, 14, len(files[0].Tracks[0].Segments[0].Points))
+
+	// segments that consist of a single stay are removed
+	singleStay := gpx.GPXTrackSegment{Points: segment.Points[3:10]}
+	segments, err = Direct(options.RemoveStays(50*unit.Metre, 5*time.Minute))(singleStay)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(segments))
+}
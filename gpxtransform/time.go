@@ -0,0 +1,38 @@
+package gpxtransform
+
+import (
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+/*
+CorrectTime creates a "GPXFileTransform"er that applies the provided time maps,
+one after another, on all timestamps of a file, i.e., on the timestamps of
+track points, route points, waypoints, and the file's metadata. Missing
+timestamps remain missing.
+*/
+func CorrectTime(timeMaps ...options.TimeMap) config.GPXFileTransform {
+	correct := func(timestamp time.Time) time.Time {
+		if timestamp.IsZero() {
+			return timestamp
+		}
+		for _, timeMap := range timeMaps {
+			timestamp = timeMap(timestamp)
+		}
+		return timestamp
+	}
+	return func(gpxFile gpx.GPX) (files []gpx.GPX, err error) {
+		if gpxFile.Time != nil {
+			fileTime := correct(*gpxFile.Time)
+			gpxFile.Time = &fileTime
+		}
+		gpxFile = options.MapPoints(gpxFile, func(point gpx.GPXPoint) gpx.GPXPoint {
+			point.Timestamp = correct(point.Timestamp)
+			return point
+		})
+		return []gpx.GPX{gpxFile}, nil
+	}
+}
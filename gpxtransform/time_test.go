@@ -0,0 +1,59 @@
+package gpxtransform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abzicht/gpsplit/gpxtransform/config"
+	"github.com/abzicht/gpsplit/gpxtransform/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+func TestCorrectTime(t *testing.T) {
+	gpxFile, err := gpx.ParseBytes([]byte(gpxDataTime))
+	assert.NoError(t, err)
+	start := time.Date(2024, 5, 6, 7, 58, 0, 0, time.UTC)
+	assert.True(t, start.Equal(gpxFile.Tracks[0].Segments[0].Points[0].Timestamp))
+
+	tc := config.NewTransformConfig(config.WithFileTransform(CorrectTime(options.ShiftTime(-90 * time.Second))))
+	gpxFiles, err := TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.True(t, start.Add(-90*time.Second).Equal(gpxFiles[0].Tracks[0].Segments[0].Points[0].Timestamp))
+	assert.True(t, start.Add(90*time.Second).Equal(gpxFiles[0].Tracks[0].Segments[0].Points[3].Timestamp))
+	// the original file is not modified
+	assert.True(t, start.Equal(gpxFile.Tracks[0].Segments[0].Points[0].Timestamp))
+
+	// 07:58 in Berlin (summer time) equals 05:58 UTC
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	tc = config.NewTransformConfig(config.WithFileTransform(CorrectTime(options.ReinterpretTimeZone(berlin))))
+	gpxFiles, err = TransformFile(*gpxFile, tc)
+	assert.NoError(t, err)
+	assert.True(t, start.Add(-2*time.Hour).Equal(gpxFiles[0].Tracks[0].Segments[0].Points[0].Timestamp))
+}
+
+func TestDriftCorrection(t *testing.T) {
+	recorded1 := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+	recorded2 := recorded1.Add(100 * time.Minute)
+	// the clock was 10s ahead at the first and 20s behind at the second reference
+	drift, err := options.DriftCorrection(recorded1, recorded1.Add(-10*time.Second), recorded2, recorded2.Add(20*time.Second))
+	assert.NoError(t, err)
+	assert.True(t, recorded1.Add(-10*time.Second).Equal(drift(recorded1)))
+	assert.True(t, recorded2.Add(20*time.Second).Equal(drift(recorded2)))
+	assert.True(t, recorded1.Add(50*time.Minute+5*time.Second).Equal(drift(recorded1.Add(50*time.Minute))))
+	assert.True(t, recorded1.Add(-50*time.Minute-25*time.Second).Equal(drift(recorded1.Add(-50*time.Minute))))
+
+	_, err = options.DriftCorrection(recorded1, recorded1, recorded1, recorded2)
+	assert.Error(t, err)
+}
+
+func TestWeekRollover(t *testing.T) {
+	recorded := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+	rollover := 1024 * 7 * 24 * time.Hour
+	repair := options.WeekRollover(time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, recorded.Equal(repair(recorded.Add(-rollover))))
+	assert.True(t, recorded.Equal(repair(recorded.Add(rollover))))
+	assert.True(t, recorded.Equal(repair(recorded.Add(-2*rollover))))
+	assert.True(t, recorded.Equal(repair(recorded)))
+}
@@ -97,3 +97,51 @@ func TransformFiles(gpxFiles []gpx.GPX, tc config.TransformConfig) ([]gpx.GPX, e
 		return gpxFiles, nil
 	}
 }
+
+/*
+Chain creates a "GPXSegmentTransform"er that applies the provided transforms
+one after another. Every transform is applied on all segments returned by its
+predecessor.
+*/
+func Chain(transforms ...config.GPXSegmentTransform) config.GPXSegmentTransform {
+	return func(trackSegment gpx.GPXTrackSegment) (segments []gpx.GPXTrackSegment, err error) {
+		segments = []gpx.GPXTrackSegment{trackSegment}
+		for _, transform := range transforms {
+			transformed := []gpx.GPXTrackSegment{}
+			for segmentIndex, _ := range segments {
+				var newSegments []gpx.GPXTrackSegment
+				newSegments, err = transform(segments[segmentIndex])
+				if err != nil {
+					return
+				}
+				transformed = append(transformed, newSegments...)
+			}
+			segments = transformed
+		}
+		return
+	}
+}
+
+/*
+ChainTrack creates a "GPXTrackTransform"er that applies the provided transforms
+one after another. Every transform is applied on all tracks returned by its
+predecessor.
+*/
+func ChainTrack(transforms ...config.GPXTrackTransform) config.GPXTrackTransform {
+	return func(gpxTrack gpx.GPXTrack) (tracks []gpx.GPXTrack, err error) {
+		tracks = []gpx.GPXTrack{gpxTrack}
+		for _, transform := range transforms {
+			transformed := []gpx.GPXTrack{}
+			for trackIndex, _ := range tracks {
+				var newTracks []gpx.GPXTrack
+				newTracks, err = transform(tracks[trackIndex])
+				if err != nil {
+					return
+				}
+				transformed = append(transformed, newTracks...)
+			}
+			tracks = transformed
+		}
+		return
+	}
+}